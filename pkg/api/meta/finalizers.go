@@ -0,0 +1,58 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package meta holds small helpers for manipulating service-catalog API
+// objects that don't belong on any single type.
+package meta
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// HasFinalizer returns true if the given object has the given finalizer in
+// its ObjectMeta.Finalizers list.
+func HasFinalizer(obj metav1.Object, finalizer string) bool {
+	for _, f := range obj.GetFinalizers() {
+		if f == finalizer {
+			return true
+		}
+	}
+	return false
+}
+
+// AddFinalizer adds the given finalizer to the object's ObjectMeta.Finalizers
+// list if it is not already present.
+func AddFinalizer(obj metav1.Object, finalizer string) error {
+	if HasFinalizer(obj, finalizer) {
+		return nil
+	}
+	obj.SetFinalizers(append(obj.GetFinalizers(), finalizer))
+	return nil
+}
+
+// RemoveFinalizer removes the given finalizer from the object's
+// ObjectMeta.Finalizers list, if present.
+func RemoveFinalizer(obj metav1.Object, finalizer string) error {
+	existing := obj.GetFinalizers()
+	updated := make([]string, 0, len(existing))
+	for _, f := range existing {
+		if f != finalizer {
+			updated = append(updated, f)
+		}
+	}
+	obj.SetFinalizers(updated)
+	return nil
+}
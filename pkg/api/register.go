@@ -0,0 +1,52 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package api holds the runtime.Scheme that the service catalog's v1alpha1
+// API types are registered against.
+package api
+
+import (
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/kubernetes-incubator/service-catalog/pkg/apis/servicecatalog/v1alpha1"
+)
+
+// v1alpha1SchemeGroupVersion is the group/version service-catalog's external
+// types are registered under.
+var v1alpha1SchemeGroupVersion = schema.GroupVersion{Group: "servicecatalog.k8s.io", Version: "v1alpha1"}
+
+// Scheme is the runtime.Scheme to which all service-catalog API types are
+// registered. The binding controller's tests use it to DeepCopy objects.
+var Scheme = runtime.NewScheme()
+
+func init() {
+	AddToScheme(Scheme)
+}
+
+// AddToScheme registers the service-catalog external types with the given
+// scheme.
+func AddToScheme(scheme *runtime.Scheme) {
+	scheme.AddKnownTypes(v1alpha1SchemeGroupVersion,
+		&v1alpha1.ServiceInstance{},
+		&v1alpha1.ServiceInstanceList{},
+		&v1alpha1.ServiceInstanceCredential{},
+		&v1alpha1.ServiceInstanceCredentialList{},
+		&v1alpha1.ClusterServiceBroker{},
+		&v1alpha1.ClusterServiceClass{},
+		&v1alpha1.ClusterServicePlan{},
+	)
+}
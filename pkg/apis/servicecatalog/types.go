@@ -0,0 +1,64 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package servicecatalog is the internal, unversioned form of the
+// service-catalog API types. v1alpha1 is converted to and from this package;
+// it exists so the API server's internal logic and storage layer don't take
+// a dependency on any one external version.
+package servicecatalog
+
+import metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+// ConditionStatus mirrors the three-state condition status used throughout
+// the Kubernetes API (True, False, Unknown).
+type ConditionStatus string
+
+// These are valid condition statuses. "ConditionTrue" means a resource is in
+// the condition. "ConditionFalse" means a resource is not in the condition.
+// "ConditionUnknown" means kubernetes can't decide if a resource is in the
+// condition or not.
+const (
+	ConditionTrue    ConditionStatus = "True"
+	ConditionFalse   ConditionStatus = "False"
+	ConditionUnknown ConditionStatus = "Unknown"
+)
+
+// ServiceInstanceCredentialConditionType represents the type of status
+// condition reported on a ServiceInstanceCredential.
+type ServiceInstanceCredentialConditionType string
+
+// These are valid conditions of a ServiceInstanceCredential.
+const (
+	// ServiceInstanceCredentialConditionReady represents whether the
+	// binding's credentials are currently usable.
+	ServiceInstanceCredentialConditionReady ServiceInstanceCredentialConditionType = "Ready"
+
+	// ServiceInstanceCredentialConditionFailed represents a terminal
+	// failure: the controller will not retry the operation that produced
+	// it until the user mutates the spec (generation bump) or deletes the
+	// object.
+	ServiceInstanceCredentialConditionFailed ServiceInstanceCredentialConditionType = "Failed"
+)
+
+// ServiceInstanceCredentialCondition contains condition information about a
+// ServiceInstanceCredential.
+type ServiceInstanceCredentialCondition struct {
+	Type               ServiceInstanceCredentialConditionType
+	Status             ConditionStatus
+	LastTransitionTime metav1.Time
+	Reason             string
+	Message            string
+}
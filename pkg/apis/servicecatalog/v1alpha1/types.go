@@ -0,0 +1,450 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/pkg/api/v1"
+)
+
+// FinalizerServiceCatalog is the finalizer added by the service-catalog
+// controller to objects it must clean up broker-side state for before they
+// are removed from etcd.
+const FinalizerServiceCatalog = "kubernetes-incubator/service-catalog"
+
+// ConditionStatus mirrors the three-state condition status used throughout
+// the Kubernetes API (True, False, Unknown).
+type ConditionStatus string
+
+// These are valid condition statuses. "ConditionTrue" means a resource is in
+// the condition. "ConditionFalse" means a resource is not in the condition.
+// "ConditionUnknown" means kubernetes can't decide if a resource is in the
+// condition or not.
+const (
+	ConditionTrue    ConditionStatus = "True"
+	ConditionFalse   ConditionStatus = "False"
+	ConditionUnknown ConditionStatus = "Unknown"
+)
+
+// UserInfo holds information about the user that last changed a resource's
+// spec, used to pass originating identity to brokers.
+type UserInfo struct {
+	Username string                `json:"username"`
+	UID      string                `json:"uid"`
+	Groups   []string              `json:"groups,omitempty"`
+	Extra    map[string]ExtraValue `json:"extra,omitempty"`
+}
+
+// ExtraValue holds extra information about a user, masked as a slice of
+// strings to keep it generic across authenticators.
+type ExtraValue []string
+
+// ServiceInstance represents a provisioned instance of a ServiceClass.
+type ServiceInstance struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ServiceInstanceSpec   `json:"spec,omitempty"`
+	Status ServiceInstanceStatus `json:"status,omitempty"`
+}
+
+// ServiceInstanceList is a list of ServiceInstances.
+type ServiceInstanceList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ServiceInstance `json:"items"`
+}
+
+// ServiceInstanceSpec describes the attributes a user specifies when
+// provisioning a new ServiceInstance.
+type ServiceInstanceSpec struct {
+	ExternalServiceClassName string `json:"externalServiceClassName"`
+	ExternalServicePlanName  string `json:"externalServicePlanName"`
+
+	ServiceClassRef *v1.ObjectReference `json:"serviceClassRef,omitempty"`
+	ServicePlanRef  *v1.ObjectReference `json:"servicePlanRef,omitempty"`
+
+	Parameters *runtime.RawExtension `json:"parameters,omitempty"`
+
+	ExternalID string `json:"externalID"`
+
+	UserInfo *UserInfo `json:"userInfo,omitempty"`
+}
+
+// ServiceInstanceStatus represents the current status of a ServiceInstance.
+type ServiceInstanceStatus struct {
+	Conditions []ServiceInstanceCondition `json:"conditions,omitempty"`
+
+	AsyncOpInProgress bool `json:"asyncOpInProgress"`
+
+	CurrentOperation     ServiceInstanceOperation        `json:"currentOperation,omitempty"`
+	ReconciledGeneration int64                           `json:"reconciledGeneration"`
+	OperationStartTime   *metav1.Time                    `json:"operationStartTime,omitempty"`
+	InProgressProperties *ServiceInstancePropertiesState `json:"inProgressProperties,omitempty"`
+	ExternalProperties   *ServiceInstancePropertiesState `json:"externalProperties,omitempty"`
+}
+
+// ServiceInstanceOperation denotes what action the controller is currently
+// taking against the broker for a ServiceInstance.
+type ServiceInstanceOperation string
+
+// These are the valid values for ServiceInstanceOperation.
+const (
+	ServiceInstanceOperationProvision   ServiceInstanceOperation = "Provision"
+	ServiceInstanceOperationUpdate      ServiceInstanceOperation = "Update"
+	ServiceInstanceOperationDeprovision ServiceInstanceOperation = "Deprovision"
+)
+
+// ServiceInstancePropertiesState captures the parameters used the last time
+// an operation against the broker was attempted or succeeded.
+type ServiceInstancePropertiesState struct {
+	Parameters         *runtime.RawExtension `json:"parameters,omitempty"`
+	ParametersChecksum string                `json:"parametersChecksum,omitempty"`
+	UserInfo           *UserInfo             `json:"userInfo,omitempty"`
+}
+
+// ServiceInstanceConditionType represents the type of status condition
+// reported on a ServiceInstance.
+type ServiceInstanceConditionType string
+
+// These are valid conditions of a ServiceInstance.
+const (
+	ServiceInstanceConditionReady  ServiceInstanceConditionType = "Ready"
+	ServiceInstanceConditionFailed ServiceInstanceConditionType = "Failed"
+)
+
+// ServiceInstanceCondition contains condition information about a
+// ServiceInstance.
+type ServiceInstanceCondition struct {
+	Type               ServiceInstanceConditionType `json:"type"`
+	Status             ConditionStatus              `json:"status"`
+	LastTransitionTime metav1.Time                  `json:"lastTransitionTime,omitempty"`
+	Reason             string                       `json:"reason,omitempty"`
+	Message            string                       `json:"message,omitempty"`
+}
+
+// ServiceInstanceCredential represents a binding of a ServiceInstance's
+// credentials into a Kubernetes Secret.
+type ServiceInstanceCredential struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ServiceInstanceCredentialSpec   `json:"spec,omitempty"`
+	Status ServiceInstanceCredentialStatus `json:"status,omitempty"`
+}
+
+// ServiceInstanceCredentialList is a list of ServiceInstanceCredentials.
+type ServiceInstanceCredentialList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ServiceInstanceCredential `json:"items"`
+}
+
+// ProjectionMode controls how a ServiceInstanceCredential's materialized
+// credentials are made available to workloads.
+type ProjectionMode string
+
+const (
+	// ProjectionModeSecret is the legacy behavior: credentials are written
+	// into an opaque v1.Secret named by Spec.SecretName.
+	ProjectionModeSecret ProjectionMode = "Secret"
+
+	// ProjectionModeServiceBinding additionally produces a
+	// servicebinding.io/v1beta1 ServiceBinding object that points at the
+	// credentials Secret, so workloads using the upstream ServiceBinding
+	// runtime can project the binding without catalog-specific glue. This
+	// replaces the removed AlphaPodPresetTemplate injection path.
+	ProjectionModeServiceBinding ProjectionMode = "ServiceBinding"
+)
+
+// WorkloadSelector expresses which workloads a ServiceBinding projection
+// should be applied to, mirroring the servicebinding.io spec's workload
+// reference/selector union.
+type WorkloadSelector struct {
+	// WorkloadRef names a specific workload resource by group/version/kind
+	// and name.
+	WorkloadRef *v1.ObjectReference `json:"workloadRef,omitempty"`
+
+	// WorkloadSelector is a label selector matched against workloads in
+	// the ServiceInstanceCredential's namespace.
+	WorkloadSelector *metav1.LabelSelector `json:"workloadSelector,omitempty"`
+}
+
+// ServiceInstanceCredentialSpec represents the desired state of a
+// ServiceInstanceCredential.
+type ServiceInstanceCredentialSpec struct {
+	ServiceInstanceRef v1.LocalObjectReference `json:"instanceRef"`
+
+	Parameters *runtime.RawExtension `json:"parameters,omitempty"`
+
+	SecretName string `json:"secretName,omitempty"`
+
+	ExternalID string `json:"externalID"`
+
+	UserInfo *UserInfo `json:"userInfo,omitempty"`
+
+	// ProjectionMode selects how materialized credentials are surfaced to
+	// workloads. Defaults to ProjectionModeSecret.
+	ProjectionMode ProjectionMode `json:"projectionMode,omitempty"`
+
+	// Workload identifies the workload(s) a ServiceBinding projection
+	// should target. Only consulted when ProjectionMode is
+	// ProjectionModeServiceBinding.
+	Workload *WorkloadSelector `json:"workload,omitempty"`
+
+	// CredentialsFormat controls how broker credential values are written
+	// into the destination Secret. Defaults to CredentialsFormatRaw.
+	CredentialsFormat CredentialsFormat `json:"credentialsFormat,omitempty"`
+
+	// CredentialsProvider selects which CredentialProvider materializes this
+	// binding's credentials. Defaults to CredentialsProviderOSBBroker, which
+	// binds against the resolved ClusterServiceBroker.
+	CredentialsProvider string `json:"credentialsProvider,omitempty"`
+
+	// CredentialsSecretRef names a Secret in the same namespace to read
+	// credentials from instead of binding against the broker. It is only
+	// consulted when CredentialsProvider is CredentialsProviderUserProvided.
+	CredentialsSecretRef *v1.LocalObjectReference `json:"credentialsSecretRef,omitempty"`
+}
+
+// These are the CredentialProvider implementations the controller ships
+// with, selectable via ServiceInstanceCredentialSpec.CredentialsProvider.
+const (
+	// CredentialsProviderOSBBroker is the default: it binds against the
+	// ServiceInstance's resolved ClusterServiceBroker.
+	CredentialsProviderOSBBroker = "osb-broker"
+
+	// CredentialsProviderUserProvided reads credentials from
+	// ServiceInstanceCredentialSpec.CredentialsSecretRef instead of calling
+	// any broker.
+	CredentialsProviderUserProvided = "user-provided"
+)
+
+// CredentialsFormat controls how a broker's Credentials map is written into
+// the destination Secret.
+type CredentialsFormat string
+
+const (
+	// CredentialsFormatRaw writes each value under its own key only,
+	// stringifying scalars and JSON-marshaling nested objects and arrays
+	// (back-compat default, also used when the field is empty).
+	CredentialsFormatRaw CredentialsFormat = "Raw"
+
+	// CredentialsFormatJSON marshals object/array values to JSON and
+	// stores the bytes under their key, leaving scalars stringified.
+	CredentialsFormatJSON CredentialsFormat = "JSON"
+
+	// CredentialsFormatFlat additionally projects nested object values as
+	// one Secret key per leaf path (e.g. "tls.ca", "tls.cert").
+	CredentialsFormatFlat CredentialsFormat = "Flat"
+)
+
+// ServiceInstanceCredentialOperation denotes what action the controller is
+// currently taking against the broker for a ServiceInstanceCredential.
+type ServiceInstanceCredentialOperation string
+
+// These are the valid values for ServiceInstanceCredentialOperation.
+const (
+	ServiceInstanceCredentialOperationBind   ServiceInstanceCredentialOperation = "Bind"
+	ServiceInstanceCredentialOperationUnbind ServiceInstanceCredentialOperation = "Unbind"
+)
+
+// ServiceInstanceCredentialPropertiesState captures the parameters used the
+// last time a Bind/Unbind operation against the broker was attempted or
+// succeeded.
+type ServiceInstanceCredentialPropertiesState struct {
+	Parameters         *runtime.RawExtension `json:"parameters,omitempty"`
+	ParametersChecksum string                `json:"parametersChecksum,omitempty"`
+	UserInfo           *UserInfo             `json:"userInfo,omitempty"`
+}
+
+// ServiceInstanceCredentialStatus represents the current status of a
+// ServiceInstanceCredential.
+type ServiceInstanceCredentialStatus struct {
+	Conditions []ServiceInstanceCredentialCondition `json:"conditions,omitempty"`
+
+	CurrentOperation     ServiceInstanceCredentialOperation        `json:"currentOperation,omitempty"`
+	ReconciledGeneration int64                                     `json:"reconciledGeneration"`
+	OperationStartTime   *metav1.Time                              `json:"operationStartTime,omitempty"`
+	InProgressProperties *ServiceInstanceCredentialPropertiesState `json:"inProgressProperties,omitempty"`
+	ExternalProperties   *ServiceInstanceCredentialPropertiesState `json:"externalProperties,omitempty"`
+
+	OrphanMitigationInProgress bool `json:"orphanMitigationInProgress"`
+
+	// UnbindStatus records whether the broker side of a binding has been
+	// confirmed torn down, used to decide whether orphan mitigation must
+	// still issue an Unbind.
+	UnbindStatus ServiceInstanceCredentialUnbindStatus `json:"unbindStatus,omitempty"`
+
+	// CredentialsObservedVersion mirrors the resourceVersion of the
+	// destination Secret as of the last time the controller wrote to it,
+	// so downstream consumers (and the controller itself, on the next
+	// reconcile) can detect whether the Secret has changed since without
+	// re-diffing its contents.
+	CredentialsObservedVersion string `json:"credentialsObservedVersion,omitempty"`
+
+	// NextRetryTime is set after a retriable (non-terminal) Bind failure to
+	// the earliest time the controller will attempt Bind again, per its
+	// exponential backoff. It is cleared once Bind succeeds or is
+	// reclassified as terminal. While OrphanMitigationInProgress is set, it
+	// instead holds the earliest time the failed cleanup Unbind will be
+	// retried, and is cleared once mitigation succeeds or times out. Surfaced on Status purely for
+	// observability; the controller does not require this field to be
+	// persisted to enforce the backoff itself.
+	NextRetryTime *metav1.Time `json:"nextRetryTime,omitempty"`
+
+	// OrphanMitigationRetries counts how many cleanup Unbind attempts have
+	// been made while OrphanMitigationInProgress is set. It is reset to 0
+	// once mitigation succeeds or is abandoned, and exists alongside
+	// NextRetryTime purely for observability into the orphan mitigation
+	// backoff; the controller tracks the authoritative count in memory via
+	// orphanMitigationBackoff.
+	OrphanMitigationRetries int64 `json:"orphanMitigationRetries,omitempty"`
+}
+
+// ServiceInstanceCredentialUnbindStatus represents the latest known state of
+// the broker-side unbind for a ServiceInstanceCredential.
+type ServiceInstanceCredentialUnbindStatus string
+
+// These are the valid values for ServiceInstanceCredentialUnbindStatus.
+const (
+	ServiceInstanceCredentialUnbindStatusNotRequired ServiceInstanceCredentialUnbindStatus = "NotRequired"
+	ServiceInstanceCredentialUnbindStatusRequired    ServiceInstanceCredentialUnbindStatus = "Required"
+	ServiceInstanceCredentialUnbindStatusSucceeded   ServiceInstanceCredentialUnbindStatus = "Succeeded"
+	ServiceInstanceCredentialUnbindStatusFailed      ServiceInstanceCredentialUnbindStatus = "Failed"
+)
+
+// ServiceInstanceCredentialConditionType represents the type of status
+// condition reported on a ServiceInstanceCredential.
+type ServiceInstanceCredentialConditionType string
+
+// These are valid conditions of a ServiceInstanceCredential.
+const (
+	// ServiceInstanceCredentialConditionReady represents whether the
+	// binding's credentials are currently usable.
+	ServiceInstanceCredentialConditionReady ServiceInstanceCredentialConditionType = "Ready"
+
+	// ServiceInstanceCredentialConditionFailed represents a terminal
+	// failure: the controller will not retry the operation that produced
+	// it until the user mutates the spec (generation bump) or deletes the
+	// object.
+	ServiceInstanceCredentialConditionFailed ServiceInstanceCredentialConditionType = "Failed"
+)
+
+// ServiceInstanceCredentialCondition contains condition information about a
+// ServiceInstanceCredential.
+type ServiceInstanceCredentialCondition struct {
+	Type               ServiceInstanceCredentialConditionType `json:"type"`
+	Status             ConditionStatus                        `json:"status"`
+	LastTransitionTime metav1.Time                            `json:"lastTransitionTime,omitempty"`
+	Reason             string                                 `json:"reason,omitempty"`
+	Message            string                                 `json:"message,omitempty"`
+}
+
+// ClusterServiceBroker represents an OSB-compatible broker registered with
+// the catalog.
+type ClusterServiceBroker struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ClusterServiceBrokerSpec   `json:"spec,omitempty"`
+	Status ClusterServiceBrokerStatus `json:"status,omitempty"`
+}
+
+// ClusterServiceBrokerSpec represents the configuration of a
+// ClusterServiceBroker.
+type ClusterServiceBrokerSpec struct {
+	URL            string `json:"url"`
+	RelistBehavior string `json:"relistBehavior,omitempty"`
+}
+
+// ClusterServiceBrokerStatus represents the current status of a
+// ClusterServiceBroker.
+type ClusterServiceBrokerStatus struct {
+	Conditions []ClusterServiceBrokerCondition `json:"conditions,omitempty"`
+}
+
+// ClusterServiceBrokerCondition contains condition information about a
+// ClusterServiceBroker.
+type ClusterServiceBrokerCondition struct {
+	Type               string          `json:"type"`
+	Status             ConditionStatus `json:"status"`
+	LastTransitionTime metav1.Time     `json:"lastTransitionTime,omitempty"`
+	Reason             string          `json:"reason,omitempty"`
+	Message            string          `json:"message,omitempty"`
+}
+
+// ClusterServiceClass represents an offering advertised by a
+// ClusterServiceBroker's catalog.
+type ClusterServiceClass struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ClusterServiceClassSpec   `json:"spec,omitempty"`
+	Status ClusterServiceClassStatus `json:"status,omitempty"`
+}
+
+// ClusterServiceClassSpec represents the attributes of a ClusterServiceClass
+// as advertised by its broker.
+type ClusterServiceClassSpec struct {
+	ClusterServiceBrokerName string `json:"clusterServiceBrokerName"`
+	ExternalName             string `json:"externalName"`
+	ExternalID               string `json:"externalID"`
+	Description              string `json:"description"`
+	Bindable                 bool   `json:"bindable"`
+}
+
+// ClusterServiceClassStatus represents the current status of a
+// ClusterServiceClass.
+type ClusterServiceClassStatus struct {
+	// RemovedFromBrokerCatalog is true when a relist of the broker's
+	// catalog no longer advertises this class. The class is kept around
+	// (rather than deleted) so that existing ServiceInstances referencing
+	// it can still be deprovisioned.
+	RemovedFromBrokerCatalog bool `json:"removedFromBrokerCatalog"`
+}
+
+// ClusterServicePlan represents a plan offered for a ClusterServiceClass.
+type ClusterServicePlan struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ClusterServicePlanSpec   `json:"spec,omitempty"`
+	Status ClusterServicePlanStatus `json:"status,omitempty"`
+}
+
+// ClusterServicePlanSpec represents the attributes of a ClusterServicePlan
+// as advertised by its broker.
+type ClusterServicePlanSpec struct {
+	ClusterServiceBrokerName string                  `json:"clusterServiceBrokerName"`
+	ClusterServiceClassRef   v1.LocalObjectReference `json:"clusterServiceClassRef"`
+	ExternalName             string                  `json:"externalName"`
+	ExternalID               string                  `json:"externalID"`
+	Description              string                  `json:"description"`
+	// Bindable overrides the owning ClusterServiceClass's bindability when
+	// set; nil means "inherit from the class".
+	Bindable *bool `json:"bindable,omitempty"`
+}
+
+// ClusterServicePlanStatus represents the current status of a
+// ClusterServicePlan.
+type ClusterServicePlanStatus struct {
+	// RemovedFromBrokerCatalog is true when a relist of the broker's
+	// catalog no longer advertises this plan.
+	RemovedFromBrokerCatalog bool `json:"removedFromBrokerCatalog"`
+}
@@ -0,0 +1,208 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package controller contains the controllers that reconcile
+// service-catalog API objects (ClusterServiceBroker, ServiceInstance,
+// ServiceInstanceCredential) against the brokers they represent.
+package controller
+
+import (
+	"time"
+
+	osb "github.com/pmorie/go-open-service-broker-client/v2"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
+
+	"github.com/kubernetes-incubator/service-catalog/pkg/apis/servicecatalog/v1alpha1"
+	clientset "github.com/kubernetes-incubator/service-catalog/pkg/client/clientset_generated/clientset"
+	informers "github.com/kubernetes-incubator/service-catalog/pkg/client/informers_generated/informers/externalversions"
+	servicecatalogv1alpha1listers "github.com/kubernetes-incubator/service-catalog/pkg/client/listers_generated/servicecatalog/v1alpha1"
+)
+
+// defaultReconciliationRetryDuration is how long the controller will keep
+// retrying a failing operation (Bind/Unbind, including orphan mitigation)
+// against a ServiceInstanceCredential before giving up and setting the
+// terminal Failed condition.
+const defaultReconciliationRetryDuration = 7 * 24 * time.Hour
+
+// defaultOrphanMitigationMaxDuration is how long the controller will keep
+// retrying the cleanup Unbind issued while a ServiceInstanceCredential's
+// OrphanMitigationInProgress is set, before giving up on mitigation and
+// setting the terminal Failed condition. Tracked separately from
+// defaultReconciliationRetryDuration so mitigation (cleaning up against a
+// broker that already failed a Bind in an ambiguous way) can be given a
+// tighter or looser budget than a fresh Bind attempt.
+const defaultOrphanMitigationMaxDuration = 7 * 24 * time.Hour
+
+// brokerClientCreateFunc builds an osb.Client for a given broker's
+// configuration. It is passed to NewController so tests can supply a
+// function that returns the shared fakeosb.FakeClient.
+type brokerClientCreateFunc func(*osb.ClientConfiguration) (osb.Client, error)
+
+// controller is the actual implementation of the service-catalog
+// controller-manager's reconciliation logic. An unexported type is used
+// (rather than exporting it) because nothing outside this package should
+// depend on its internals; NewController returns a *controller.
+type controller struct {
+	kubeClient    kubernetes.Interface
+	catalogClient clientset.Interface
+
+	// dynamicClient is used to manage servicebinding.io ServiceBinding
+	// objects, an API group service-catalog does not itself own and so
+	// has no generated clientset for.
+	dynamicClient dynamic.Interface
+
+	brokerClientCreateFunc brokerClientCreateFunc
+
+	// credentialProviders maps a ServiceInstanceCredential's
+	// Spec.CredentialsProvider value to the CredentialProvider that services
+	// it. It is populated by NewController and not mutated afterward, so
+	// reads from the reconcile loop need no locking.
+	credentialProviders map[string]CredentialProvider
+
+	// bindingRetryBackoff throttles how often a ServiceInstanceCredential
+	// with a retriable (non-terminal) Bind failure is retried. Its schedule
+	// is configured via NewController's bindBackoffBase/bindBackoffMax
+	// parameters.
+	bindingRetryBackoff *bindingRetryBackoff
+
+	// orphanMitigationBackoff throttles how often the controller retries the
+	// cleanup Unbind issued while a ServiceInstanceCredential's
+	// OrphanMitigationInProgress is set. It is tracked separately from
+	// bindingRetryBackoff so an operator can back off orphan cleanup (against
+	// a broker that's already shown it's struggling) on a different schedule
+	// than a fresh Bind attempt. Configured via NewController's
+	// orphanMitigationBackoffBase/orphanMitigationBackoffMax parameters.
+	orphanMitigationBackoff *bindingRetryBackoff
+
+	brokerLister       servicecatalogv1alpha1listers.ClusterServiceBrokerLister
+	serviceClassLister servicecatalogv1alpha1listers.ClusterServiceClassLister
+	servicePlanLister  servicecatalogv1alpha1listers.ClusterServicePlanLister
+	instanceLister     servicecatalogv1alpha1listers.ServiceInstanceLister
+	bindingLister      servicecatalogv1alpha1listers.ServiceInstanceCredentialLister
+
+	bindingQueue workqueue.RateLimitingInterface
+
+	recorder record.EventRecorder
+
+	// reconciliationRetryDuration bounds how long the controller will keep
+	// retrying a retriable failure before treating it as terminal. It
+	// defaults to defaultReconciliationRetryDuration but is overridable so
+	// tests (and, eventually, controller-manager flags) don't have to wait
+	// out the real default.
+	reconciliationRetryDuration time.Duration
+
+	// orphanMitigationMaxDuration bounds how long the controller will keep
+	// retrying orphan mitigation's cleanup Unbind before treating it as
+	// terminal, independently of reconciliationRetryDuration. Configured via
+	// NewController's orphanMitigationMaxDuration parameter.
+	orphanMitigationMaxDuration time.Duration
+
+	// clock is the controller's source of the current time, used for every
+	// backoff/timeout computation in the binding reconciler. It defaults to
+	// clock.RealClock{} but is overridable so tests can drive retry/timeout
+	// logic deterministically instead of sleeping or faking stale timestamps
+	// against the real wall clock.
+	clock clock.Clock
+}
+
+// NewController returns a new service-catalog controller for the Binding,
+// Instance, and Broker resources, wired up to watch the given informers.
+func NewController(
+	kubeClient kubernetes.Interface,
+	catalogClient clientset.Interface,
+	dynamicClient dynamic.Interface,
+	informerFactory informers.SharedInformerFactory,
+	brokerClientCreateFunc brokerClientCreateFunc,
+	reconciliationRetryDuration time.Duration,
+	bindBackoffBase time.Duration,
+	bindBackoffMax time.Duration,
+	orphanMitigationBackoffBase time.Duration,
+	orphanMitigationBackoffMax time.Duration,
+	orphanMitigationJitterFactor float64,
+	orphanMitigationMaxDuration time.Duration,
+	recorder record.EventRecorder,
+	clk clock.Clock,
+) (*controller, error) {
+	scInformers := informerFactory.Servicecatalog().V1alpha1()
+
+	if reconciliationRetryDuration <= 0 {
+		reconciliationRetryDuration = defaultReconciliationRetryDuration
+	}
+	if orphanMitigationMaxDuration <= 0 {
+		orphanMitigationMaxDuration = defaultOrphanMitigationMaxDuration
+	}
+	if clk == nil {
+		clk = clock.RealClock{}
+	}
+
+	c := &controller{
+		kubeClient:             kubeClient,
+		catalogClient:          catalogClient,
+		dynamicClient:          dynamicClient,
+		brokerClientCreateFunc: brokerClientCreateFunc,
+		credentialProviders: map[string]CredentialProvider{
+			v1alpha1.CredentialsProviderOSBBroker:    OSBCredentialProvider{},
+			v1alpha1.CredentialsProviderUserProvided: NewStaticCredentialProvider(kubeClient),
+		},
+		bindingRetryBackoff:         newBindingRetryBackoff(bindBackoffBase, bindBackoffMax),
+		orphanMitigationBackoff:     newBindingRetryBackoffWithJitter(orphanMitigationBackoffBase, orphanMitigationBackoffMax, orphanMitigationJitterFactor),
+		brokerLister:                scInformers.ClusterServiceBrokers().Lister(),
+		serviceClassLister:          scInformers.ServiceClasses().Lister(),
+		servicePlanLister:           scInformers.ServicePlans().Lister(),
+		instanceLister:              scInformers.ServiceInstances().Lister(),
+		bindingLister:               scInformers.ServiceInstanceCredentials().Lister(),
+		bindingQueue:                workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "service-instance-credential"),
+		recorder:                    recorder,
+		reconciliationRetryDuration: reconciliationRetryDuration,
+		orphanMitigationMaxDuration: orphanMitigationMaxDuration,
+		clock:                       clk,
+	}
+
+	scInformers.ServiceInstanceCredentials().Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    c.bindingAdd,
+		UpdateFunc: c.bindingUpdate,
+		DeleteFunc: c.bindingDelete,
+	})
+
+	return c, nil
+}
+
+func (c *controller) bindingAdd(obj interface{}) {
+	key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
+	if err != nil {
+		return
+	}
+	c.bindingQueue.Add(key)
+}
+
+func (c *controller) bindingUpdate(oldObj, newObj interface{}) {
+	c.bindingAdd(newObj)
+}
+
+func (c *controller) bindingDelete(obj interface{}) {
+	c.bindingAdd(obj)
+}
+
+// strPtr returns a pointer to s. Shared by production code building OSB
+// requests and by tests constructing expected requests to compare against.
+func strPtr(s string) *string {
+	return &s
+}
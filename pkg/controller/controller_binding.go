@@ -0,0 +1,749 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+	"net/http"
+
+	osb "github.com/pmorie/go-open-service-broker-client/v2"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/pkg/api/v1"
+
+	scmeta "github.com/kubernetes-incubator/service-catalog/pkg/api/meta"
+	"github.com/kubernetes-incubator/service-catalog/pkg/apis/servicecatalog/v1alpha1"
+)
+
+const (
+	errorNonexistentServiceInstanceReason          = "ReferencesNonexistentServiceInstance"
+	errorNonexistentServiceClassMessage            = "ReferencesNonexistentServiceClass"
+	errorNonbindableServiceClassReason             = "ErrorNonbindableServiceClass"
+	errorServiceInstanceNotReadyReason             = "ErrorServiceInstanceNotReady"
+	errorFindingNamespaceServiceInstanceReason     = "ErrorFindingNamespaceForServiceInstance"
+	errorWithOngoingAsyncOperation                 = "ErrorAsyncOperationInProgress"
+	errorWithParameters                            = "ErrorWithParameters"
+	errorDeletedClusterServiceClassReason          = "ReferencesDeletedServiceClass"
+	errorDeletedClusterServicePlanReason           = "ReferencesDeletedServicePlan"
+	errorInjectingBindResultReason                 = "ErrorInjectingBindResult"
+	errorBindCallReason                            = "BindCallFailed"
+	errorUnbindCallReason                          = "UnbindCallFailed"
+	errorMissingCredentialsSecretRefReason         = "ErrorMissingCredentialsSecretRef"
+	errorUnknownCredentialsProviderReason          = "ErrorUnknownCredentialsProvider"
+	errorReconciliationRetryTimeoutReason          = "ReconciliationRetryTimeout"
+	errorOrphanMitigationTimeoutReason             = "OrphanMitigationTimeout"
+	errorServiceInstanceCredentialOrphanMitigation = "OrphanMitigation"
+	errorDeletedServiceBrokerReason                = "ReferencesDeletedServiceBroker"
+	errorBindingFailedReason                       = "BindingFailed"
+
+	successInjectedBindResultReason  = "InjectedBindResult"
+	successInjectedBindResultMessage = "Injected bind result"
+	successUnboundReason             = "UnboundSuccessfully"
+	successOrphanMitigationReason    = "OrphanMitigationSuccessful"
+	successBindingStartedReason      = "BindingStarted"
+)
+
+// errSecretConflict is returned by injectServiceInstanceCredential when the
+// destination Secret already exists and is owned by something other than
+// the binding. This is a persistent, user-caused conflict (fixable only by
+// changing Spec.SecretName or the Secret itself), so callers treat it as
+// non-retriable.
+var errSecretConflict = fmt.Errorf("destination secret is not owned by this ServiceInstanceCredential")
+
+// reconcileServiceInstanceCredential is the entry point of the control loop
+// for ServiceInstanceCredential objects. It dispatches to
+// reconcileServiceInstanceCredentialAdd (which itself hands off to
+// reconcileServiceInstanceCredentialPoll once a Bind is underway) or
+// reconcileServiceInstanceCredentialDelete, depending on whether binding is
+// being torn down.
+func (c *controller) reconcileServiceInstanceCredential(binding *v1alpha1.ServiceInstanceCredential) error {
+	if binding.DeletionTimestamp != nil {
+		return c.reconcileServiceInstanceCredentialDelete(binding)
+	}
+	return c.reconcileServiceInstanceCredentialAdd(binding)
+}
+
+// reconcileServiceInstanceCredentialAdd drives a binding from its current
+// observed state to its desired spec by, as needed, looking up the
+// referenced ServiceInstance/ClusterServiceClass/ClusterServicePlan, calling
+// Bind against the broker, and materializing the resulting credentials into
+// a Secret. Each invocation performs at most one status update and returns;
+// the next tick (triggered by that update) picks up where this one left
+// off, so a slow or conflicting API server can't cause the same Bind to be
+// issued twice.
+func (c *controller) reconcileServiceInstanceCredentialAdd(binding *v1alpha1.ServiceInstanceCredential) error {
+	if isServiceInstanceCredentialFailed(binding) && binding.Status.ReconciledGeneration == binding.Generation {
+		return nil
+	}
+
+	// The ServiceInstance/ClusterServiceClass/ClusterServicePlan lookups and
+	// the deleted-from-catalog guard below run unconditionally on every
+	// invocation, including ticks where a Bind is already in progress, so a
+	// class or plan removed from the broker's catalog mid-Bind is caught
+	// before the next poll rather than only at the start of the operation.
+	instance, err := c.instanceLister.ServiceInstances(binding.Namespace).Get(binding.Spec.ServiceInstanceRef.Name)
+	if err != nil {
+		s := fmt.Sprintf(
+			"ServiceInstanceCredential %q references a non-existent ServiceInstance %q",
+			binding.Namespace+"/"+binding.Name, binding.Spec.ServiceInstanceRef.Name,
+		)
+		c.recorder.Event(binding, v1.EventTypeWarning, errorNonexistentServiceInstanceReason, s)
+		if _, updateErr := c.updateServiceInstanceCredentialCondition(
+			binding,
+			v1alpha1.ServiceInstanceCredentialConditionReady,
+			v1alpha1.ConditionFalse,
+			errorNonexistentServiceInstanceReason,
+			s,
+		); updateErr != nil {
+			return updateErr
+		}
+		return err
+	}
+
+	if instance.Spec.ServiceClassRef == nil {
+		return fmt.Errorf(
+			"ServiceInstance %q references a ServiceClass that has not been resolved yet",
+			instance.Namespace+"/"+instance.Name,
+		)
+	}
+	if instance.Spec.ServicePlanRef == nil {
+		return fmt.Errorf(
+			"ServiceInstance %q references a ServicePlan that has not been resolved yet",
+			instance.Namespace+"/"+instance.Name,
+		)
+	}
+
+	serviceClass, servicePlan, broker, brokerClient, err := c.getServiceClassPlanAndBrokerForInstance(instance)
+	if err != nil {
+		s := fmt.Sprintf(
+			"ServiceInstanceCredential %q references a non-existent ServiceClass %q",
+			binding.Namespace+"/"+binding.Name, instance.Spec.ExternalServiceClassName,
+		)
+		c.recorder.Event(binding, v1.EventTypeWarning, errorNonexistentServiceClassMessage, s)
+		if _, updateErr := c.updateServiceInstanceCredentialCondition(
+			binding,
+			v1alpha1.ServiceInstanceCredentialConditionReady,
+			v1alpha1.ConditionFalse,
+			errorNonexistentServiceClassMessage,
+			s,
+		); updateErr != nil {
+			return updateErr
+		}
+		return nil
+	}
+
+	if broker.DeletionTimestamp != nil {
+		s := fmt.Sprintf(
+			"ServiceInstanceCredential %q references a ClusterServiceBroker %q that is being deleted",
+			binding.Namespace+"/"+binding.Name, broker.Name,
+		)
+		return c.rejectServiceInstanceCredentialForDeletedCatalogEntry(binding, errorDeletedServiceBrokerReason, s)
+	}
+
+	if serviceClass.Status.RemovedFromBrokerCatalog || serviceClass.DeletionTimestamp != nil {
+		s := fmt.Sprintf(
+			"ServiceInstanceCredential %q references a ClusterServiceClass %q that has been deleted from the broker catalog",
+			binding.Namespace+"/"+binding.Name, serviceClass.Name,
+		)
+		return c.rejectServiceInstanceCredentialForDeletedCatalogEntry(binding, errorDeletedClusterServiceClassReason, s)
+	}
+
+	if servicePlan.Status.RemovedFromBrokerCatalog || servicePlan.DeletionTimestamp != nil {
+		s := fmt.Sprintf(
+			"ServiceInstanceCredential %q references a ClusterServicePlan %q that has been deleted from the broker catalog",
+			binding.Namespace+"/"+binding.Name, servicePlan.Name,
+		)
+		return c.rejectServiceInstanceCredentialForDeletedCatalogEntry(binding, errorDeletedClusterServicePlanReason, s)
+	}
+
+	if instance.Status.AsyncOpInProgress {
+		s := fmt.Sprintf(
+			"ServiceInstanceCredential %q references ServiceInstance %q which has ongoing asynchronous operation",
+			binding.Namespace+"/"+binding.Name, instance.Namespace+"/"+instance.Name,
+		)
+		c.recorder.Event(binding, v1.EventTypeWarning, errorWithOngoingAsyncOperation, s)
+		if _, updateErr := c.updateServiceInstanceCredentialCondition(
+			binding,
+			v1alpha1.ServiceInstanceCredentialConditionReady,
+			v1alpha1.ConditionFalse,
+			errorWithOngoingAsyncOperation,
+			s,
+		); updateErr != nil {
+			return updateErr
+		}
+		return fmt.Errorf("ServiceInstance %q has Ongoing Asynchronous operation in progress", instance.Namespace+"/"+instance.Name)
+	}
+
+	if !isPlanBindable(serviceClass, servicePlan) {
+		s := fmt.Sprintf(
+			`ServiceInstanceCredential %q references a non-bindable ServiceClass (%q) and Plan (%q) combination`,
+			binding.Namespace+"/"+binding.Name, serviceClass.Name, servicePlan.Name,
+		)
+		c.recorder.Event(binding, v1.EventTypeWarning, errorNonbindableServiceClassReason, s)
+		if _, updateErr := c.updateServiceInstanceCredentialFailure(binding, errorNonbindableServiceClassReason, s); updateErr != nil {
+			return updateErr
+		}
+		return nil
+	}
+
+	if !isServiceInstanceReady(instance) {
+		s := fmt.Sprintf(
+			`ServiceInstanceCredential cannot begin because referenced instance %q is not ready`,
+			instance.Namespace+"/"+instance.Name,
+		)
+		c.recorder.Event(binding, v1.EventTypeWarning, errorServiceInstanceNotReadyReason, s)
+		if _, updateErr := c.updateServiceInstanceCredentialCondition(
+			binding,
+			v1alpha1.ServiceInstanceCredentialConditionReady,
+			v1alpha1.ConditionFalse,
+			errorServiceInstanceNotReadyReason,
+			s,
+		); updateErr != nil {
+			return updateErr
+		}
+		return nil
+	}
+
+	if binding.Status.CurrentOperation != v1alpha1.ServiceInstanceCredentialOperationBind {
+		_, parametersChecksum, err := buildParameters(binding.Spec.Parameters)
+		if err != nil {
+			s := fmt.Sprintf("Error unmarshaling ServiceInstanceCredential %q parameters: %s", binding.Namespace+"/"+binding.Name, err)
+			c.recorder.Event(binding, v1.EventTypeWarning, errorWithParameters, s)
+			if _, updateErr := c.updateServiceInstanceCredentialFailure(binding, errorWithParameters, s); updateErr != nil {
+				return updateErr
+			}
+			return nil
+		}
+
+		// Record that a Bind is starting and return immediately: the
+		// status update this produces re-enqueues the binding, and the
+		// actual broker call happens on that next iteration. This keeps
+		// each reconcile call to at most one status update, so a slow API
+		// server can't cause the same Bind to be issued twice.
+		_, err = c.recordStartOfServiceInstanceCredentialOperation(binding, v1alpha1.ServiceInstanceCredentialOperationBind, binding.Spec.Parameters, parametersChecksum)
+		return err
+	}
+
+	return c.reconcileServiceInstanceCredentialPoll(binding, instance, serviceClass, servicePlan, broker, brokerClient)
+}
+
+// rejectServiceInstanceCredentialForDeletedCatalogEntry records a warning
+// event and sets Ready=False with reason on binding, for the case where the
+// ClusterServiceBroker, ClusterServiceClass, or ClusterServicePlan it
+// resolves to has been removed from the broker's catalog (or is itself being
+// deleted). It never returns an error on its own: the rejection is reported
+// through the binding's status, not by failing reconciliation.
+func (c *controller) rejectServiceInstanceCredentialForDeletedCatalogEntry(binding *v1alpha1.ServiceInstanceCredential, reason, message string) error {
+	c.recorder.Event(binding, v1.EventTypeWarning, reason, message)
+	if _, err := c.updateServiceInstanceCredentialCondition(
+		binding,
+		v1alpha1.ServiceInstanceCredentialConditionReady,
+		v1alpha1.ConditionFalse,
+		reason,
+		message,
+	); err != nil {
+		return err
+	}
+	return nil
+}
+
+// credentialProviderFor resolves binding.Spec.CredentialsProvider to the
+// CredentialProvider registered for it, falling back to
+// CredentialsProviderOSBBroker when unset. It is a lookup into a fixed,
+// never-mutated map, so it's safe to call without locking.
+func (c *controller) credentialProviderFor(binding *v1alpha1.ServiceInstanceCredential) CredentialProvider {
+	name := binding.Spec.CredentialsProvider
+	if name == "" {
+		name = v1alpha1.CredentialsProviderOSBBroker
+	}
+	return c.credentialProviders[name]
+}
+
+// reconcileServiceInstanceCredentialPoll is the third phase of reconciling a
+// binding that is not being deleted, alongside reconcileServiceInstanceCredentialAdd
+// (which resolves references and records that a Bind is starting) and
+// reconcileServiceInstanceCredentialDelete. It runs once binding's
+// Status.CurrentOperation is already ServiceInstanceCredentialOperationBind
+// (i.e. the in-progress status update from reconcileServiceInstanceCredentialAdd
+// has already been persisted) and performs the actual broker Bind call and
+// credential materialization, issuing at most one further status update
+// before returning.
+func (c *controller) reconcileServiceInstanceCredentialPoll(
+	binding *v1alpha1.ServiceInstanceCredential,
+	instance *v1alpha1.ServiceInstance,
+	serviceClass *v1alpha1.ClusterServiceClass,
+	servicePlan *v1alpha1.ClusterServicePlan,
+	broker *v1alpha1.ClusterServiceBroker,
+	brokerClient osb.Client,
+) error {
+	if binding.Status.OrphanMitigationInProgress {
+		if c.orphanMitigationTimedOut(binding) {
+			return c.failServiceInstanceCredentialOnOrphanMitigationTimeout(binding)
+		}
+		if readyAt := c.orphanMitigationBackoff.readyAt(binding.UID); c.clock.Now().Before(readyAt) {
+			return nil
+		}
+		return c.reconcileServiceInstanceCredentialOrphanMitigation(binding, instance, serviceClass, servicePlan, brokerClient)
+	}
+
+	if c.serviceInstanceCredentialReconciliationTimedOut(binding) {
+		return c.failServiceInstanceCredentialOnReconciliationTimeout(binding)
+	}
+
+	if readyAt := c.bindingRetryBackoff.readyAt(binding.UID); c.clock.Now().Before(readyAt) {
+		return nil
+	}
+
+	provider := c.credentialProviderFor(binding)
+	if provider == nil {
+		s := fmt.Sprintf(
+			"ServiceInstanceCredential %q has unknown credentialsProvider %q",
+			binding.Namespace+"/"+binding.Name, binding.Spec.CredentialsProvider,
+		)
+		c.recorder.Event(binding, v1.EventTypeWarning, errorUnknownCredentialsProviderReason, s)
+		_, updateErr := c.updateServiceInstanceCredentialFailure(binding, errorUnknownCredentialsProviderReason, s)
+		return updateErr
+	}
+
+	ns, err := c.kubeClient.CoreV1().Namespaces().Get(instance.Namespace, metav1.GetOptions{})
+	if err != nil {
+		s := fmt.Sprintf("Failed to get namespace %q during binding: %s", instance.Namespace, err)
+		c.recorder.Event(binding, v1.EventTypeWarning, errorFindingNamespaceServiceInstanceReason, s)
+		if _, updateErr := c.updateServiceInstanceCredentialCondition(
+			binding,
+			v1alpha1.ServiceInstanceCredentialConditionReady,
+			v1alpha1.ConditionFalse,
+			errorFindingNamespaceServiceInstanceReason,
+			s,
+		); updateErr != nil {
+			return updateErr
+		}
+		return err
+	}
+
+	credentials, err := provider.Provide(binding, instance, serviceClass, servicePlan, broker, brokerClient, ns)
+	if err != nil {
+		s := err.Error()
+		c.recorder.Event(binding, v1.EventTypeWarning, errorBindCallReason, s)
+		if providerErr, ok := err.(*CredentialProviderError); ok {
+			if providerErr.Terminal {
+				c.bindingRetryBackoff.forget(binding.UID)
+				binding.Status.NextRetryTime = nil
+				c.recorder.Event(binding, v1.EventTypeWarning, errorBindingFailedReason, s)
+				_, updateErr := c.updateServiceInstanceCredentialFailure(binding, providerErr.Reason, s)
+				return updateErr
+			}
+			c.bindingRetryBackoff.recordFailure(binding.UID, c.clock.Now())
+			nextRetry := metav1.NewTime(c.bindingRetryBackoff.readyAt(binding.UID))
+			binding.Status.NextRetryTime = &nextRetry
+			binding.Status.OrphanMitigationInProgress = providerErr.Orphan
+			_, updateErr := c.updateServiceInstanceCredentialCondition(
+				binding,
+				v1alpha1.ServiceInstanceCredentialConditionReady,
+				v1alpha1.ConditionFalse,
+				providerErr.Reason,
+				s,
+			)
+			return updateErr
+		}
+		c.bindingRetryBackoff.recordFailure(binding.UID, c.clock.Now())
+		nextRetry := metav1.NewTime(c.bindingRetryBackoff.readyAt(binding.UID))
+		binding.Status.NextRetryTime = &nextRetry
+		if _, updateErr := c.updateServiceInstanceCredentialCondition(
+			binding,
+			v1alpha1.ServiceInstanceCredentialConditionReady,
+			v1alpha1.ConditionFalse,
+			errorBindCallReason,
+			s,
+		); updateErr != nil {
+			return updateErr
+		}
+		return err
+	}
+	c.bindingRetryBackoff.forget(binding.UID)
+	binding.Status.NextRetryTime = nil
+
+	err = c.injectServiceInstanceCredential(binding, credentials)
+	if err != nil {
+		s := fmt.Sprintf("Error injecting binding result: %s", err)
+		c.recorder.Event(binding, v1.EventTypeWarning, errorInjectingBindResultReason, s)
+		if err == errSecretConflict {
+			c.recorder.Event(binding, v1.EventTypeWarning, errorBindingFailedReason, s)
+			_, updateErr := c.updateServiceInstanceCredentialFailure(binding, errorInjectingBindResultReason, s)
+			return updateErr
+		}
+		if _, updateErr := c.updateServiceInstanceCredentialCondition(
+			binding,
+			v1alpha1.ServiceInstanceCredentialConditionReady,
+			v1alpha1.ConditionFalse,
+			errorInjectingBindResultReason,
+			s,
+		); updateErr != nil {
+			return updateErr
+		}
+		return err
+	}
+
+	if err := c.projectServiceBinding(binding, serviceClass, broker); err != nil {
+		return err
+	}
+
+	c.recorder.Event(binding, v1.EventTypeNormal, successInjectedBindResultReason, successInjectedBindResultMessage)
+	_, err = c.recordSuccessfulServiceInstanceCredentialOperation(binding, v1alpha1.ServiceInstanceCredentialOperationBind)
+	return err
+}
+
+// serviceInstanceCredentialReconciliationTimedOut reports whether binding's
+// current Bind attempt has been retrying for longer than
+// c.reconciliationRetryDuration, and should therefore be given up on. Called
+// only while OrphanMitigationInProgress is unset; once mitigation is
+// underway, orphanMitigationTimedOut and c.orphanMitigationMaxDuration apply
+// instead.
+func (c *controller) serviceInstanceCredentialReconciliationTimedOut(binding *v1alpha1.ServiceInstanceCredential) bool {
+	return binding.Status.OperationStartTime != nil && c.clock.Now().Sub(binding.Status.OperationStartTime.Time) > c.reconciliationRetryDuration
+}
+
+// failServiceInstanceCredentialOnReconciliationTimeout sets the terminal
+// Failed condition on binding because c.reconciliationRetryDuration has
+// elapsed without a successful Bind.
+func (c *controller) failServiceInstanceCredentialOnReconciliationTimeout(binding *v1alpha1.ServiceInstanceCredential) error {
+	s := fmt.Sprintf(
+		"Stopping reconciliation retries on ServiceInstanceCredential %q because too much time has elapsed since the operation started: %v",
+		binding.Namespace+"/"+binding.Name, c.reconciliationRetryDuration,
+	)
+	c.recorder.Event(binding, v1.EventTypeWarning, errorReconciliationRetryTimeoutReason, s)
+	c.bindingRetryBackoff.forget(binding.UID)
+	binding.Status.NextRetryTime = nil
+	_, updateErr := c.updateServiceInstanceCredentialFailure(binding, errorReconciliationRetryTimeoutReason, s)
+	return updateErr
+}
+
+// orphanMitigationTimedOut reports whether binding's orphan mitigation has
+// been retrying for longer than c.orphanMitigationMaxDuration, and should
+// therefore be given up on. It reuses Status.OperationStartTime, the same
+// marker serviceInstanceCredentialReconciliationTimedOut checks against the
+// Bind attempt that preceded mitigation, since entering mitigation never
+// resets it.
+func (c *controller) orphanMitigationTimedOut(binding *v1alpha1.ServiceInstanceCredential) bool {
+	return binding.Status.OperationStartTime != nil && c.clock.Now().Sub(binding.Status.OperationStartTime.Time) > c.orphanMitigationMaxDuration
+}
+
+// failServiceInstanceCredentialOnOrphanMitigationTimeout sets the terminal
+// Failed condition on binding because c.orphanMitigationMaxDuration has
+// elapsed without a successful cleanup Unbind. There is nothing left to
+// retry once the binding is Failed, so both backoff trackers are forgotten.
+func (c *controller) failServiceInstanceCredentialOnOrphanMitigationTimeout(binding *v1alpha1.ServiceInstanceCredential) error {
+	s := fmt.Sprintf(
+		"Stopping orphan mitigation retries on ServiceInstanceCredential %q because too much time has elapsed since mitigation started: %v",
+		binding.Namespace+"/"+binding.Name, c.orphanMitigationMaxDuration,
+	)
+	c.recorder.Event(binding, v1.EventTypeWarning, errorOrphanMitigationTimeoutReason, s)
+	c.bindingRetryBackoff.forget(binding.UID)
+	c.orphanMitigationBackoff.forget(binding.UID)
+	binding.Status.NextRetryTime = nil
+	binding.Status.OrphanMitigationRetries = 0
+	_, updateErr := c.updateServiceInstanceCredentialFailure(binding, errorOrphanMitigationTimeoutReason, s)
+	return updateErr
+}
+
+// reconcileServiceInstanceCredentialOrphanMitigation runs instead of a
+// regular Bind attempt while binding.Status.OrphanMitigationInProgress is
+// set: an earlier Bind failed in a way that left it unclear whether the
+// broker actually created the binding, so before retrying Bind the
+// controller first issues an Unbind to clean up any such orphan. It backs
+// off on its own schedule via orphanMitigationBackoff, separate from the
+// bindingRetryBackoff used for Bind attempts, and is bounded by the separate
+// orphanMitigationMaxDuration timeout checked in
+// reconcileServiceInstanceCredentialPoll.
+func (c *controller) reconcileServiceInstanceCredentialOrphanMitigation(
+	binding *v1alpha1.ServiceInstanceCredential,
+	instance *v1alpha1.ServiceInstance,
+	serviceClass *v1alpha1.ClusterServiceClass,
+	servicePlan *v1alpha1.ClusterServicePlan,
+	brokerClient osb.Client,
+) error {
+	if binding.Spec.SecretName != "" {
+		err := c.kubeClient.CoreV1().Secrets(binding.Namespace).Delete(binding.Spec.SecretName, &metav1.DeleteOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+	}
+	if err := c.deleteServiceBindingProjection(binding); err != nil {
+		return err
+	}
+
+	_, err := brokerClient.Unbind(&osb.UnbindRequest{
+		BindingID:  binding.Spec.ExternalID,
+		InstanceID: instance.Spec.ExternalID,
+		ServiceID:  serviceClass.Spec.ExternalID,
+		PlanID:     servicePlan.Spec.ExternalID,
+	})
+	if err != nil {
+		s := fmt.Sprintf(
+			"Error mitigating orphan for ServiceInstanceCredential %q: %s",
+			binding.Namespace+"/"+binding.Name, err,
+		)
+		c.recorder.Event(binding, v1.EventTypeWarning, errorServiceInstanceCredentialOrphanMitigation, s)
+		c.orphanMitigationBackoff.recordFailure(binding.UID, c.clock.Now())
+		nextRetry := metav1.NewTime(c.orphanMitigationBackoff.readyAt(binding.UID))
+		binding.Status.NextRetryTime = &nextRetry
+		binding.Status.OrphanMitigationRetries++
+		_, updateErr := c.updateServiceInstanceCredentialCondition(
+			binding,
+			v1alpha1.ServiceInstanceCredentialConditionReady,
+			v1alpha1.ConditionFalse,
+			errorServiceInstanceCredentialOrphanMitigation,
+			s,
+		)
+		return updateErr
+	}
+
+	c.orphanMitigationBackoff.forget(binding.UID)
+	binding.Status.NextRetryTime = nil
+	binding.Status.OrphanMitigationInProgress = false
+	binding.Status.OrphanMitigationRetries = 0
+	s := fmt.Sprintf(
+		"Orphan mitigation succeeded for ServiceInstanceCredential %q; Bind will be retried",
+		binding.Namespace+"/"+binding.Name,
+	)
+	c.recorder.Event(binding, v1.EventTypeNormal, successOrphanMitigationReason, s)
+	_, err = c.updateServiceInstanceCredentialCondition(
+		binding,
+		v1alpha1.ServiceInstanceCredentialConditionReady,
+		v1alpha1.ConditionFalse,
+		successOrphanMitigationReason,
+		s,
+	)
+	return err
+}
+
+// reconcileServiceInstanceCredentialDelete handles the deprovisioning path:
+// calling Unbind against the broker, deleting the projected Secret, and
+// removing the service-catalog finalizer so the object can be garbage
+// collected.
+func (c *controller) reconcileServiceInstanceCredentialDelete(binding *v1alpha1.ServiceInstanceCredential) error {
+	if !scmeta.HasFinalizer(binding, v1alpha1.FinalizerServiceCatalog) {
+		return nil
+	}
+
+	instance, err := c.instanceLister.ServiceInstances(binding.Namespace).Get(binding.Spec.ServiceInstanceRef.Name)
+	if err != nil {
+		return err
+	}
+	serviceClass, servicePlan, _, brokerClient, err := c.getServiceClassPlanAndBrokerForInstance(instance)
+	if err != nil {
+		return err
+	}
+
+	// The Unbind already completed (successfully or not); only removing the
+	// finalizer remains, and that's a plain metadata Update with no broker
+	// call behind it, so it's safe to do in this same invocation.
+	if binding.Status.CurrentOperation == "" && binding.Status.ReconciledGeneration == binding.Generation {
+		return c.removeServiceInstanceCredentialFinalizer(binding)
+	}
+
+	if binding.Status.CurrentOperation != v1alpha1.ServiceInstanceCredentialOperationUnbind {
+		// Record that an Unbind is starting and return immediately: the
+		// status update this produces re-enqueues the binding, and the
+		// actual broker call happens on that next iteration, mirroring the
+		// Bind-side split in reconcileServiceInstanceCredentialAdd.
+		_, err = c.recordStartOfServiceInstanceCredentialOperation(binding, v1alpha1.ServiceInstanceCredentialOperationUnbind, nil, "")
+		return err
+	}
+
+	return c.reconcileServiceInstanceCredentialUnbind(binding, instance, serviceClass, servicePlan, brokerClient)
+}
+
+// reconcileServiceInstanceCredentialUnbind performs the actual broker Unbind
+// call for a binding whose Status.CurrentOperation is already
+// ServiceInstanceCredentialOperationUnbind. It performs at most one further
+// status update before returning; the finalizer is removed on a later
+// iteration once that update has landed, by
+// reconcileServiceInstanceCredentialDelete.
+func (c *controller) reconcileServiceInstanceCredentialUnbind(
+	binding *v1alpha1.ServiceInstanceCredential,
+	instance *v1alpha1.ServiceInstance,
+	serviceClass *v1alpha1.ClusterServiceClass,
+	servicePlan *v1alpha1.ClusterServicePlan,
+	brokerClient osb.Client,
+) error {
+	if binding.Spec.SecretName != "" {
+		err := c.kubeClient.CoreV1().Secrets(binding.Namespace).Delete(binding.Spec.SecretName, &metav1.DeleteOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+	}
+
+	if err := c.deleteServiceBindingProjection(binding); err != nil {
+		return err
+	}
+
+	// A binding whose credentials came from somewhere other than the broker
+	// (CredentialsProviderUserProvided) was never Bound against it, so there
+	// is nothing for the broker to Unbind either.
+	if binding.Spec.CredentialsProvider == v1alpha1.CredentialsProviderUserProvided {
+		c.recorder.Event(binding, v1.EventTypeNormal, successUnboundReason, "This binding was deleted successfully")
+		c.bindingRetryBackoff.forget(binding.UID)
+		c.orphanMitigationBackoff.forget(binding.UID)
+		_, err := c.recordSuccessfulServiceInstanceCredentialOperation(binding, v1alpha1.ServiceInstanceCredentialOperationUnbind)
+		return err
+	}
+
+	request := &osb.UnbindRequest{
+		BindingID:  binding.Spec.ExternalID,
+		InstanceID: instance.Spec.ExternalID,
+		ServiceID:  serviceClass.Spec.ExternalID,
+		PlanID:     servicePlan.Spec.ExternalID,
+	}
+	if utilfeatureOriginatingIdentityEnabled() {
+		request.OriginatingIdentity = toOSBOriginatingIdentity(binding.Spec.UserInfo)
+	}
+	_, err := brokerClient.Unbind(request)
+	if err != nil {
+		s := fmt.Sprintf("Error unbinding ServiceInstanceCredential %q: %s", binding.Namespace+"/"+binding.Name, err)
+		c.recorder.Event(binding, v1.EventTypeWarning, errorUnbindCallReason, s)
+		_, updateErr := c.updateServiceInstanceCredentialCondition(
+			binding,
+			v1alpha1.ServiceInstanceCredentialConditionReady,
+			v1alpha1.ConditionFalse,
+			errorUnbindCallReason,
+			s,
+		)
+		return updateErr
+	}
+
+	c.recorder.Event(binding, v1.EventTypeNormal, successUnboundReason, "This binding was deleted successfully")
+	c.bindingRetryBackoff.forget(binding.UID)
+	c.orphanMitigationBackoff.forget(binding.UID)
+	_, err = c.recordSuccessfulServiceInstanceCredentialOperation(binding, v1alpha1.ServiceInstanceCredentialOperationUnbind)
+	return err
+}
+
+// removeServiceInstanceCredentialFinalizer removes the service-catalog
+// finalizer from binding and persists the result, allowing the API server to
+// garbage collect the object.
+func (c *controller) removeServiceInstanceCredentialFinalizer(binding *v1alpha1.ServiceInstanceCredential) error {
+	if err := scmeta.RemoveFinalizer(binding, v1alpha1.FinalizerServiceCatalog); err != nil {
+		return err
+	}
+	_, err := c.catalogClient.ServicecatalogV1alpha1().ServiceInstanceCredentials(binding.Namespace).Update(binding)
+	return err
+}
+
+// injectServiceInstanceCredential writes a broker's returned credentials into
+// the destination Secret, creating it if it doesn't exist and confirming
+// ownership if it does. The shape of secretData is controlled by
+// binding.Spec.CredentialsFormat; see credentialsToSecretData.
+func (c *controller) injectServiceInstanceCredential(binding *v1alpha1.ServiceInstanceCredential, credentials map[string]interface{}) error {
+	secretData, err := credentialsToSecretData(credentials, binding.Spec.CredentialsFormat)
+	if err != nil {
+		return err
+	}
+
+	secretClient := c.kubeClient.CoreV1().Secrets(binding.Namespace)
+	existingSecret, err := secretClient.Get(binding.Spec.SecretName, metav1.GetOptions{})
+	if err == nil {
+		if !IsControlledBy(existingSecret, binding) {
+			return errSecretConflict
+		}
+		existingSecret.Data = secretData
+		updated, err := secretClient.Update(existingSecret)
+		if err != nil {
+			return err
+		}
+		binding.Status.CredentialsObservedVersion = updated.ResourceVersion
+		return nil
+	}
+	if !apierrors.IsNotFound(err) {
+		return err
+	}
+
+	controllerRef := metav1.NewControllerRef(binding, v1alpha1SchemeGroupVersionKind)
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            binding.Spec.SecretName,
+			Namespace:       binding.Namespace,
+			OwnerReferences: []metav1.OwnerReference{*controllerRef},
+		},
+		Data: secretData,
+	}
+	created, err := secretClient.Create(secret)
+	if err != nil {
+		return err
+	}
+	binding.Status.CredentialsObservedVersion = created.ResourceVersion
+	return nil
+}
+
+// isNonRetryableBindHTTPStatus reports whether statusCode, returned by the
+// broker for a Bind call, indicates a request the controller should not
+// retry without a spec change: any 4xx response except the two the OSB spec
+// reserves for transient request-rate/timeout conditions.
+func isNonRetryableBindHTTPStatus(statusCode int) bool {
+	if statusCode < 400 || statusCode >= 500 {
+		return false
+	}
+	return statusCode != http.StatusRequestTimeout && statusCode != http.StatusTooManyRequests
+}
+
+// isAmbiguousBindHTTPStatus reports whether statusCode, returned by the
+// broker for a Bind call, leaves it ambiguous whether the broker created the
+// binding before the call failed: 201 (Created, but the client still
+// observed a Bind error), a request timeout, or any 5xx. A Bind that fails
+// with one of these should be followed by orphan mitigation rather than a
+// plain retry.
+func isAmbiguousBindHTTPStatus(statusCode int) bool {
+	return statusCode == http.StatusCreated || statusCode == http.StatusRequestTimeout || statusCode >= 500
+}
+
+// terminalBindErrorMessages lists OSB ErrorMessage values known to indicate
+// a Bind will never succeed without a spec change, even on a status code
+// that would otherwise be treated as retriable.
+var terminalBindErrorMessages = map[string]bool{
+	"AsyncRequired":                   true,
+	"ServiceInstanceCredentialExists": true,
+	"ConcurrencyError":                true,
+}
+
+// isTerminalBindErrorMessage reports whether an OSB error's ErrorMessage
+// field names one of terminalBindErrorMessages.
+func isTerminalBindErrorMessage(errorMessage string) bool {
+	return terminalBindErrorMessages[errorMessage]
+}
+
+func isPlanBindable(serviceClass *v1alpha1.ClusterServiceClass, servicePlan *v1alpha1.ClusterServicePlan) bool {
+	if servicePlan.Spec.Bindable != nil {
+		return *servicePlan.Spec.Bindable
+	}
+	return serviceClass.Spec.Bindable
+}
+
+func isServiceInstanceReady(instance *v1alpha1.ServiceInstance) bool {
+	for _, c := range instance.Status.Conditions {
+		if c.Type == v1alpha1.ServiceInstanceConditionReady {
+			return c.Status == v1alpha1.ConditionTrue
+		}
+	}
+	return false
+}
+
+func strVal(s *string) string {
+	if s == nil {
+		return "<nil>"
+	}
+	return *s
+}
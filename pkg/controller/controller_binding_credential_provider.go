@@ -0,0 +1,200 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+
+	osb "github.com/pmorie/go-open-service-broker-client/v2"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/pkg/api/v1"
+
+	"github.com/kubernetes-incubator/service-catalog/pkg/apis/servicecatalog/v1alpha1"
+)
+
+// CredentialProvider obtains the credential values to materialize into a
+// ServiceInstanceCredential's destination Secret. The controller keeps
+// ownership of namespace lookups, writing the Secret, finalizers,
+// conditions, and orphan mitigation; a CredentialProvider's only job is
+// answering "what are the credentials for this binding". OSBCredentialProvider,
+// which binds against the instance's resolved broker, is registered by
+// default; alternative implementations (a credential Secret copied from
+// another namespace, one populated by a vault CSI driver, a static fixture
+// in tests) can be substituted without forking the reconcile loop.
+type CredentialProvider interface {
+	// Provide returns the credential values for binding's current Bind
+	// operation against instance. A failure that should stop the controller
+	// from retrying (and set the terminal Failed condition instead of
+	// retrying) must be returned as a *CredentialProviderError with
+	// Terminal set to true.
+	Provide(
+		binding *v1alpha1.ServiceInstanceCredential,
+		instance *v1alpha1.ServiceInstance,
+		serviceClass *v1alpha1.ClusterServiceClass,
+		servicePlan *v1alpha1.ClusterServicePlan,
+		broker *v1alpha1.ClusterServiceBroker,
+		brokerClient osb.Client,
+		namespace *v1.Namespace,
+	) (map[string]interface{}, error)
+}
+
+// CredentialProviderError wraps a CredentialProvider failure with the reason
+// string the controller should report to the user, and with whether the
+// failure is terminal (set the Failed condition) or transient (report
+// Ready=False and retry once bindingRetryBackoff allows) — mirroring how this package
+// already classifies OSB Bind HTTP errors.
+type CredentialProviderError struct {
+	Terminal bool
+	Reason   string
+
+	// Orphan indicates a Bind failure ambiguous enough that the broker may
+	// have created the binding despite returning an error (a 201, a request
+	// timeout, or any 5xx response). The controller responds by driving
+	// binding through orphan mitigation (an Unbind, to clean up a possible
+	// orphan) before retrying Bind, rather than retrying Bind directly.
+	Orphan bool
+
+	err error
+}
+
+func (e *CredentialProviderError) Error() string {
+	return e.err.Error()
+}
+
+func newCredentialProviderError(terminal bool, reason string, err error) *CredentialProviderError {
+	return &CredentialProviderError{Terminal: terminal, Reason: reason, err: err}
+}
+
+// OSBCredentialProvider is the default CredentialProvider: it issues a Bind
+// call against brokerClient and returns the broker's Credentials.
+type OSBCredentialProvider struct{}
+
+// Provide implements CredentialProvider.
+func (OSBCredentialProvider) Provide(
+	binding *v1alpha1.ServiceInstanceCredential,
+	instance *v1alpha1.ServiceInstance,
+	serviceClass *v1alpha1.ClusterServiceClass,
+	servicePlan *v1alpha1.ClusterServicePlan,
+	broker *v1alpha1.ClusterServiceBroker,
+	brokerClient osb.Client,
+	namespace *v1.Namespace,
+) (map[string]interface{}, error) {
+	parameters, _, err := buildParameters(binding.Spec.Parameters)
+	if err != nil {
+		return nil, newCredentialProviderError(true, errorWithParameters,
+			fmt.Errorf("error unmarshaling ServiceInstanceCredential %q parameters: %s", binding.Namespace+"/"+binding.Name, err))
+	}
+
+	request := &osb.BindRequest{
+		BindingID:  binding.Spec.ExternalID,
+		InstanceID: instance.Spec.ExternalID,
+		ServiceID:  serviceClass.Spec.ExternalID,
+		PlanID:     servicePlan.Spec.ExternalID,
+		AppGUID:    strPtr(string(namespace.UID)),
+		Parameters: parameters,
+		BindResource: &osb.BindResource{
+			AppGUID: strPtr(string(namespace.UID)),
+		},
+	}
+	if utilfeatureOriginatingIdentityEnabled() {
+		request.OriginatingIdentity = toOSBOriginatingIdentity(binding.Spec.UserInfo)
+	}
+
+	response, err := brokerClient.Bind(request)
+	if err != nil {
+		if httpErr, ok := osb.IsHTTPError(err); ok {
+			s := fmt.Sprintf(
+				`Error creating ServiceInstanceCredential %q for ServiceInstance %q of ServiceClass %q at ClusterServiceBroker %q, Status: %v; ErrorMessage: %v; Description: %v; ResponseError: %v`,
+				binding.Name+"/"+binding.Namespace, instance.Namespace+"/"+instance.Name, serviceClass.Spec.ExternalName, broker.Name,
+				httpErr.StatusCode, strVal(httpErr.ErrorMessage), strVal(httpErr.Description), httpErr.ResponseError,
+			)
+			terminal := isNonRetryableBindHTTPStatus(httpErr.StatusCode) || isTerminalBindErrorMessage(strVal(httpErr.ErrorMessage))
+			cpErr := newCredentialProviderError(terminal, errorBindCallReason, fmt.Errorf(s))
+			// A response the broker actually sent back (201, a request
+			// timeout, or any 5xx) means it may have created the binding
+			// before failing; treat those as a possible orphan rather than
+			// simply retrying Bind. A response that unambiguously didn't
+			// create anything (anything else, including plain 4xx) doesn't.
+			cpErr.Orphan = isAmbiguousBindHTTPStatus(httpErr.StatusCode)
+			return nil, cpErr
+		}
+
+		s := fmt.Sprintf(
+			`Error creating ServiceInstanceCredential %q for ServiceInstance %q of ServiceClass %q at ClusterServiceBroker %q: %s`,
+			binding.Name+"/"+binding.Namespace, instance.Namespace+"/"+instance.Name, serviceClass.Spec.ExternalName, broker.Name, err,
+		)
+		// No HTTP response at all (e.g. the connection dropped before the
+		// broker could reply) means the broker never got far enough to
+		// create anything, so there's nothing to mitigate; just retry Bind.
+		// It is returned as a plain error, which the controller reports as a
+		// retriable failure and also returns from the reconcile call.
+		return nil, fmt.Errorf(s)
+	}
+
+	return response.Credentials, nil
+}
+
+// StaticCredentialProvider is a CredentialProvider that skips the broker
+// entirely and reads credentials from a pre-existing Secret in the
+// binding's namespace, named by binding.Spec.CredentialsSecretRef. It exists
+// for ServiceInstanceCredentials whose values are provisioned out of band
+// (by a human, or by something like a vault CSI driver) rather than
+// returned from a live Bind call.
+type StaticCredentialProvider struct {
+	kubeClient kubernetes.Interface
+}
+
+// NewStaticCredentialProvider returns a StaticCredentialProvider that reads
+// source Secrets through kubeClient.
+func NewStaticCredentialProvider(kubeClient kubernetes.Interface) *StaticCredentialProvider {
+	return &StaticCredentialProvider{kubeClient: kubeClient}
+}
+
+// Provide implements CredentialProvider. It ignores serviceClass, servicePlan,
+// broker, and brokerClient entirely; the only input that matters is
+// binding's CredentialsSecretRef.
+func (p *StaticCredentialProvider) Provide(
+	binding *v1alpha1.ServiceInstanceCredential,
+	instance *v1alpha1.ServiceInstance,
+	serviceClass *v1alpha1.ClusterServiceClass,
+	servicePlan *v1alpha1.ClusterServicePlan,
+	broker *v1alpha1.ClusterServiceBroker,
+	brokerClient osb.Client,
+	namespace *v1.Namespace,
+) (map[string]interface{}, error) {
+	if binding.Spec.CredentialsSecretRef == nil || binding.Spec.CredentialsSecretRef.Name == "" {
+		return nil, newCredentialProviderError(true, errorMissingCredentialsSecretRefReason,
+			fmt.Errorf("ServiceInstanceCredential %q has no credentialsSecretRef to read static credentials from", binding.Namespace+"/"+binding.Name))
+	}
+
+	secret, err := p.kubeClient.CoreV1().Secrets(binding.Namespace).Get(binding.Spec.CredentialsSecretRef.Name, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, newCredentialProviderError(false, errorMissingCredentialsSecretRefReason,
+				fmt.Errorf("Secret %q referenced by credentialsSecretRef does not exist yet", binding.Namespace+"/"+binding.Spec.CredentialsSecretRef.Name))
+		}
+		return nil, newCredentialProviderError(false, errorMissingCredentialsSecretRefReason, err)
+	}
+
+	credentials := make(map[string]interface{}, len(secret.Data))
+	for k, v := range secret.Data {
+		credentials[k] = string(v)
+	}
+	return credentials, nil
+}
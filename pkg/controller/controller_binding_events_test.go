@@ -0,0 +1,56 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+	"strings"
+)
+
+// checkEvents reports a diff-style error if actual doesn't hold exactly the
+// events in expected, in order, where each expected[i] only needs to be a
+// prefix of actual[i] (most callers care about the event's type and reason,
+// not the full, often-dynamic message). This replaces the repeated
+// count-then-index-then-HasPrefix checks that used to be hand-rolled in
+// every binding reconciliation test.
+func checkEvents(actual []string, expected []string) error {
+	if e, a := len(expected), len(actual); e != a {
+		return fmt.Errorf("unexpected number of events: %s", expectedGot(e, a))
+	}
+
+	for i := range expected {
+		if e, a := expected[i], actual[i]; !strings.HasPrefix(a, e) {
+			return fmt.Errorf("unexpected event at index %v: %s", i, expectedGot(e, a))
+		}
+	}
+	return nil
+}
+
+// expectedGot formats a standard "expected X, got Y" message for use in a
+// t.Errorf/t.Fatalf call, so individual assertions don't each invent their
+// own wording for the same comparison.
+func expectedGot(expected, got interface{}) string {
+	return fmt.Sprintf("expected: %v\ngot:      %v", expected, got)
+}
+
+// bindingParameters is a test fixture mirroring a plausible caller-supplied
+// Spec.Parameters payload; it is marshaled to JSON and round-tripped through
+// osb.BindRequest.Parameters in the binding tests.
+type bindingParameters struct {
+	Name string   `json:"name"`
+	Args []string `json:"args,omitempty"`
+}
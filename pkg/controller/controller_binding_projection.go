@@ -0,0 +1,150 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/kubernetes-incubator/service-catalog/pkg/apis/servicecatalog/v1alpha1"
+)
+
+// serviceBindingGVR identifies the servicebinding.io/v1beta1 ServiceBinding
+// resource that projectServiceBinding manages. It is looked up dynamically
+// (rather than through a generated clientset) because the catalog does not
+// own this API group.
+var serviceBindingGVR = schema.GroupVersionResource{
+	Group:    "servicebinding.io",
+	Version:  "v1beta1",
+	Resource: "servicebindings",
+}
+
+// wellKnownSecretType and wellKnownSecretProvider are the standard keys the
+// servicebinding.io Provisioned Service contract expects to find in a
+// projected credentials Secret, in addition to whatever the broker itself
+// returned.
+const (
+	wellKnownSecretTypeKey     = "type"
+	wellKnownSecretProviderKey = "provider"
+)
+
+// projectServiceBinding ensures a servicebinding.io ServiceBinding exists
+// that references binding's credentials Secret, when
+// Spec.ProjectionMode == ProjectionModeServiceBinding. This is additive to
+// (never a replacement for) the opaque Secret created by
+// injectServiceInstanceCredential, and is the standards-compliant
+// replacement for the removed AlphaPodPresetTemplate injection path.
+func (c *controller) projectServiceBinding(binding *v1alpha1.ServiceInstanceCredential, serviceClass *v1alpha1.ClusterServiceClass, broker *v1alpha1.ClusterServiceBroker) error {
+	if binding.Spec.ProjectionMode != v1alpha1.ProjectionModeServiceBinding {
+		return nil
+	}
+
+	if err := c.ensureWellKnownSecretKeys(binding, serviceClass, broker); err != nil {
+		return fmt.Errorf("failed to add servicebinding.io keys to secret %q: %v", binding.Spec.SecretName, err)
+	}
+
+	sb := newServiceBindingUnstructured(binding)
+	_, err := c.dynamicClient.Resource(serviceBindingGVR).Namespace(binding.Namespace).Create(sb, metav1.CreateOptions{})
+	if apierrors.IsAlreadyExists(err) {
+		return nil
+	}
+	return err
+}
+
+// deleteServiceBindingProjection removes the servicebinding.io ServiceBinding
+// created by projectServiceBinding, mirroring it into the orphan-mitigation
+// and normal-delete paths so a retired ServiceInstanceCredential doesn't
+// leave a dangling standards object behind.
+func (c *controller) deleteServiceBindingProjection(binding *v1alpha1.ServiceInstanceCredential) error {
+	if binding.Spec.ProjectionMode != v1alpha1.ProjectionModeServiceBinding {
+		return nil
+	}
+	err := c.dynamicClient.Resource(serviceBindingGVR).Namespace(binding.Namespace).Delete(binding.Name, &metav1.DeleteOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+// ensureWellKnownSecretKeys adds the "type" and "provider" keys the
+// servicebinding.io spec requires into binding's credentials Secret,
+// deriving them from the owning ClusterServiceClass's external name and the
+// ClusterServiceBroker's name, respectively.
+func (c *controller) ensureWellKnownSecretKeys(binding *v1alpha1.ServiceInstanceCredential, serviceClass *v1alpha1.ClusterServiceClass, broker *v1alpha1.ClusterServiceBroker) error {
+	secretClient := c.kubeClient.CoreV1().Secrets(binding.Namespace)
+	secret, err := secretClient.Get(binding.Spec.SecretName, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	if secret.Data == nil {
+		secret.Data = map[string][]byte{}
+	}
+	if _, ok := secret.Data[wellKnownSecretTypeKey]; !ok {
+		secret.Data[wellKnownSecretTypeKey] = []byte(serviceClass.Spec.ExternalName)
+	}
+	if _, ok := secret.Data[wellKnownSecretProviderKey]; !ok {
+		secret.Data[wellKnownSecretProviderKey] = []byte(broker.Name)
+	}
+	updated, err := secretClient.Update(secret)
+	if err != nil {
+		return err
+	}
+	binding.Status.CredentialsObservedVersion = updated.ResourceVersion
+	return nil
+}
+
+// newServiceBindingUnstructured builds the servicebinding.io ServiceBinding
+// object for binding, targeting the workload(s) named on its spec and
+// pointing at its credentials Secret.
+func newServiceBindingUnstructured(binding *v1alpha1.ServiceInstanceCredential) *unstructured.Unstructured {
+	sb := &unstructured.Unstructured{}
+	sb.SetGroupVersionKind(serviceBindingGVR.GroupVersion().WithKind("ServiceBinding"))
+	sb.SetName(binding.Name)
+	sb.SetNamespace(binding.Namespace)
+	sb.SetOwnerReferences([]metav1.OwnerReference{*metav1.NewControllerRef(binding, v1alpha1SchemeGroupVersionKind)})
+
+	// ServiceInstanceCredential has no status.binding.name, so it can't serve
+	// as a servicebinding.io Provisioned Service duck type itself; point
+	// spec.service directly at the credentials Secret instead, which the
+	// spec supports as the direct-reference shortcut for that contract.
+	spec := map[string]interface{}{
+		"service": map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "Secret",
+			"name":       binding.Spec.SecretName,
+		},
+	}
+	if w := binding.Spec.Workload; w != nil {
+		if w.WorkloadRef != nil {
+			spec["workload"] = map[string]interface{}{
+				"apiVersion": w.WorkloadRef.APIVersion,
+				"kind":       w.WorkloadRef.Kind,
+				"name":       w.WorkloadRef.Name,
+			}
+		} else if w.WorkloadSelector != nil {
+			spec["workload"] = map[string]interface{}{
+				"selector": w.WorkloadSelector,
+			}
+		}
+	}
+	sb.Object["spec"] = spec
+	return sb
+}
@@ -0,0 +1,142 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	// defaultBindingRetryBackoffBase is the delay before the first retry of a
+	// retriable Bind failure, used unless NewController is given an override.
+	defaultBindingRetryBackoffBase = 1 * time.Second
+
+	// defaultBindingRetryBackoffMax caps how long the controller will wait
+	// between retries of a persistently-failing, but not terminal, Bind,
+	// used unless NewController is given an override.
+	defaultBindingRetryBackoffMax = 1 * time.Hour
+)
+
+// bindingRetryState tracks consecutive retriable failures for a single
+// ServiceInstanceCredential.
+type bindingRetryState struct {
+	attempts  uint
+	nextRetry time.Time
+}
+
+// bindingRetryBackoff tracks, per ServiceInstanceCredential UID, how many
+// consecutive retriable Bind failures have occurred and when the next
+// attempt may be made. Without it, a broker that is transiently down gets
+// hammered with a Bind call on every reconcile tick; with it, the interval
+// between attempts grows exponentially up to max.
+type bindingRetryBackoff struct {
+	mutex sync.Mutex
+	state map[types.UID]*bindingRetryState
+
+	base time.Duration
+	max  time.Duration
+
+	// jitterFactor randomizes each computed delay by up to this fraction in
+	// either direction, so that bindings which failed at the same moment
+	// (e.g. because the broker went down) don't all retry in lockstep and
+	// pile onto it again at the same instant. Zero (the default for a
+	// bindingRetryBackoff built via newBindingRetryBackoff) disables
+	// jittering; orphanMitigationBackoff is given a nonzero factor via
+	// newBindingRetryBackoffWithJitter.
+	jitterFactor float64
+}
+
+// newBindingRetryBackoff returns an empty bindingRetryBackoff that delays the
+// first retry by base, doubling on each subsequent failure up to max, with
+// no jitter. A zero base or max falls back to defaultBindingRetryBackoffBase
+// or defaultBindingRetryBackoffMax respectively, so existing callers that
+// don't care about the schedule can pass the zero value.
+func newBindingRetryBackoff(base, max time.Duration) *bindingRetryBackoff {
+	return newBindingRetryBackoffWithJitter(base, max, 0)
+}
+
+// newBindingRetryBackoffWithJitter is newBindingRetryBackoff, additionally
+// randomizing each computed delay by up to jitterFactor in either direction.
+// A jitterFactor of 0 behaves exactly like newBindingRetryBackoff.
+func newBindingRetryBackoffWithJitter(base, max time.Duration, jitterFactor float64) *bindingRetryBackoff {
+	if base <= 0 {
+		base = defaultBindingRetryBackoffBase
+	}
+	if max <= 0 {
+		max = defaultBindingRetryBackoffMax
+	}
+	return &bindingRetryBackoff{
+		state:        make(map[types.UID]*bindingRetryState),
+		base:         base,
+		max:          max,
+		jitterFactor: jitterFactor,
+	}
+}
+
+// readyAt reports the next time a Bind attempt is permitted for uid. The
+// zero Time means now.
+func (b *bindingRetryBackoff) readyAt(uid types.UID) time.Time {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	s, ok := b.state[uid]
+	if !ok {
+		return time.Time{}
+	}
+	return s.nextRetry
+}
+
+// recordFailure bumps uid's attempt count and schedules its next allowed
+// retry using exponential backoff (min(b.max, b.base * 2^attempts)),
+// randomized by b.jitterFactor if set.
+func (b *bindingRetryBackoff) recordFailure(uid types.UID, now time.Time) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	s, ok := b.state[uid]
+	if !ok {
+		s = &bindingRetryState{}
+		b.state[uid] = s
+	}
+	delay := b.base << s.attempts
+	if delay <= 0 || delay > b.max {
+		delay = b.max
+	}
+	if b.jitterFactor > 0 {
+		delay = jitter(delay, b.jitterFactor)
+	}
+	s.attempts++
+	s.nextRetry = now.Add(delay)
+}
+
+// jitter scales delay by a uniformly random factor in
+// [1-jitterFactor, 1+jitterFactor].
+func jitter(delay time.Duration, jitterFactor float64) time.Duration {
+	factor := 1 + jitterFactor*(2*rand.Float64()-1)
+	return time.Duration(float64(delay) * factor)
+}
+
+// forget clears any recorded failures for uid. Called once a Bind succeeds
+// or is classified as a terminal failure, since there is nothing left to
+// back off from.
+func (b *bindingRetryBackoff) forget(uid types.UID) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	delete(b.state, uid)
+}
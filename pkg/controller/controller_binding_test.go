@@ -35,6 +35,7 @@ import (
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/diff"
 	utilfeature "k8s.io/apiserver/pkg/util/feature"
 
@@ -280,7 +281,19 @@ func TestReconcileServiceInstanceCredentialWithSecretConflict(t *testing.T) {
 		},
 	}
 
-	err := testController.reconcileServiceInstanceCredential(binding)
+	// First call only records the start of the Bind operation and returns;
+	// the broker isn't called until the second.
+	if err := testController.reconcileServiceInstanceCredential(binding); err != nil {
+		t.Fatalf("recording the start of the operation should not fail: %v", err)
+	}
+
+	actions := fakeCatalogClient.Actions()
+	assertNumberOfActions(t, actions, 1)
+
+	updatedServiceInstanceCredential := assertUpdateStatus(t, actions[0], binding).(*v1alpha1.ServiceInstanceCredential)
+	assertServiceInstanceCredentialOperationInProgress(t, updatedServiceInstanceCredential, v1alpha1.ServiceInstanceCredentialOperationBind, binding)
+
+	err := testController.reconcileServiceInstanceCredential(updatedServiceInstanceCredential)
 	if err == nil {
 		t.Fatalf("a binding should fail to create a secret: %v", err)
 	}
@@ -298,13 +311,10 @@ func TestReconcileServiceInstanceCredentialWithSecretConflict(t *testing.T) {
 		},
 	})
 
-	actions := fakeCatalogClient.Actions()
+	actions = fakeCatalogClient.Actions()
 	assertNumberOfActions(t, actions, 2)
 
-	updatedServiceInstanceCredential := assertUpdateStatus(t, actions[0], binding).(*v1alpha1.ServiceInstanceCredential)
-	assertServiceInstanceCredentialOperationInProgress(t, updatedServiceInstanceCredential, v1alpha1.ServiceInstanceCredentialOperationBind, binding)
-
-	updatedServiceInstanceCredential = assertUpdateStatus(t, actions[1], binding).(*v1alpha1.ServiceInstanceCredential)
+	updatedServiceInstanceCredential = assertUpdateStatus(t, actions[1], updatedServiceInstanceCredential).(*v1alpha1.ServiceInstanceCredential)
 	assertServiceInstanceCredentialReadyFalse(t, updatedServiceInstanceCredential, errorInjectingBindResultReason)
 	assertServiceInstanceCredentialCurrentOperation(t, updatedServiceInstanceCredential, v1alpha1.ServiceInstanceCredentialOperationBind)
 	assertServiceInstanceCredentialOperationStartTimeSet(t, updatedServiceInstanceCredential, true)
@@ -380,11 +390,44 @@ func TestReconcileServiceInstanceCredentialWithParameters(t *testing.T) {
 	}
 	binding.Spec.Parameters = &runtime.RawExtension{Raw: b}
 
+	expectedParameters := map[string]interface{}{
+		"args": []interface{}{
+			"first-arg",
+			"second-arg",
+		},
+		"name": "test-param",
+	}
+	expectedParametersChecksum, err := generateChecksumOfParameters(expectedParameters)
+	if err != nil {
+		t.Fatalf("Failed to generate parameters checksum: %v", err)
+	}
+
+	// First iteration: reconcileServiceInstanceCredential only records the
+	// start of the Bind operation and returns, so the broker isn't called
+	// yet and exactly one catalog-client action results.
 	err = testController.reconcileServiceInstanceCredential(binding)
 	if err != nil {
 		t.Fatalf("a valid binding should not fail: %v", err)
 	}
 
+	assertNumberOfClusterServiceBrokerActions(t, fakeClusterServiceBrokerClient.Actions(), 0)
+
+	actions := fakeCatalogClient.Actions()
+	assertNumberOfActions(t, actions, 1)
+
+	updatedServiceInstanceCredential := assertUpdateStatus(t, actions[0], binding).(*v1alpha1.ServiceInstanceCredential)
+	assertServiceInstanceCredentialOperationInProgressWithParameters(t, updatedServiceInstanceCredential, v1alpha1.ServiceInstanceCredentialOperationBind, expectedParameters, expectedParametersChecksum, binding)
+	assertServiceInstanceCredentialOrphanMitigationSet(t, updatedServiceInstanceCredential, false)
+
+	// Second iteration: the informer would have delivered the object
+	// reconcileServiceInstanceCredential just wrote back (now carrying
+	// CurrentOperation/InProgressProperties); feed that in and this call
+	// issues the actual Bind and injects the result.
+	err = testController.reconcileServiceInstanceCredential(updatedServiceInstanceCredential)
+	if err != nil {
+		t.Fatalf("a valid binding should not fail: %v", err)
+	}
+
 	brokerActions := fakeClusterServiceBrokerClient.Actions()
 	assertNumberOfClusterServiceBrokerActions(t, brokerActions, 1)
 	assertBind(t, brokerActions[0], &osb.BindRequest{
@@ -405,26 +448,10 @@ func TestReconcileServiceInstanceCredentialWithParameters(t *testing.T) {
 		},
 	})
 
-	expectedParameters := map[string]interface{}{
-		"args": []interface{}{
-			"first-arg",
-			"second-arg",
-		},
-		"name": "test-param",
-	}
-	expectedParametersChecksum, err := generateChecksumOfParameters(expectedParameters)
-	if err != nil {
-		t.Fatalf("Failed to generate parameters checksum: %v", err)
-	}
-
-	actions := fakeCatalogClient.Actions()
+	actions = fakeCatalogClient.Actions()
 	assertNumberOfActions(t, actions, 2)
 
-	updatedServiceInstanceCredential := assertUpdateStatus(t, actions[0], binding).(*v1alpha1.ServiceInstanceCredential)
-	assertServiceInstanceCredentialOperationInProgressWithParameters(t, updatedServiceInstanceCredential, v1alpha1.ServiceInstanceCredentialOperationBind, expectedParameters, expectedParametersChecksum, binding)
-	assertServiceInstanceCredentialOrphanMitigationSet(t, updatedServiceInstanceCredential, false)
-
-	updatedServiceInstanceCredential = assertUpdateStatus(t, actions[1], binding).(*v1alpha1.ServiceInstanceCredential)
+	updatedServiceInstanceCredential = assertUpdateStatus(t, actions[1], updatedServiceInstanceCredential).(*v1alpha1.ServiceInstanceCredential)
 	assertServiceInstanceCredentialOperationSuccessWithParameters(t, updatedServiceInstanceCredential, v1alpha1.ServiceInstanceCredentialOperationBind, expectedParameters, expectedParametersChecksum, binding)
 	assertServiceInstanceCredentialOrphanMitigationSet(t, updatedServiceInstanceCredential, false)
 
@@ -1179,29 +1206,41 @@ func TestReconcileServiceInstanceCredentialWithClusterServiceBrokerError(t *test
 		},
 	}
 
+	// First iteration just records the start of the Bind and returns,
+	// without calling the broker.
 	err := testController.reconcileServiceInstanceCredential(binding)
-	if err == nil {
-		t.Fatal("reconcileServiceInstanceCredential should have returned an error")
+	if err != nil {
+		t.Fatalf("recording the start of the operation should not fail: %v", err)
 	}
 
 	actions := fakeCatalogClient.Actions()
-	assertNumberOfActions(t, actions, 2)
+	assertNumberOfActions(t, actions, 1)
 
 	updatedServiceInstanceCredential := assertUpdateStatus(t, actions[0], binding)
 	assertServiceInstanceCredentialOperationInProgress(t, updatedServiceInstanceCredential, v1alpha1.ServiceInstanceCredentialOperationBind, binding)
 	assertServiceInstanceCredentialOrphanMitigationSet(t, updatedServiceInstanceCredential, false)
 
-	updatedServiceInstanceCredential = assertUpdateStatus(t, actions[1], binding)
+	// Second iteration, fed the object the first call wrote back, issues
+	// the failing Bind.
+	err = testController.reconcileServiceInstanceCredential(updatedServiceInstanceCredential.(*v1alpha1.ServiceInstanceCredential))
+	if err == nil {
+		t.Fatal("reconcileServiceInstanceCredential should have returned an error")
+	}
+
+	actions = fakeCatalogClient.Actions()
+	assertNumberOfActions(t, actions, 2)
+
+	updatedServiceInstanceCredential = assertUpdateStatus(t, actions[1], updatedServiceInstanceCredential)
 	assertServiceInstanceCredentialRequestRetriableError(t, updatedServiceInstanceCredential, v1alpha1.ServiceInstanceCredentialOperationBind, errorBindCallReason, binding)
 	assertServiceInstanceCredentialOrphanMitigationSet(t, updatedServiceInstanceCredential, false)
 
 	events := getRecordedEvents(testController)
-	expectedEvent := apiv1.EventTypeWarning + " " + errorBindCallReason + " " + `Error creating ServiceInstanceCredential "test-binding/test-ns" for ServiceInstance "test-ns/test-instance" of ServiceClass "test-serviceclass" at ClusterServiceBroker "test-broker": Unexpected action`
-	if 1 != len(events) {
-		t.Fatalf("Did not record expected event, expecting: %v", expectedEvent)
+	expectedEvents := []string{
+		apiv1.EventTypeNormal + " " + successBindingStartedReason,
+		apiv1.EventTypeWarning + " " + errorBindCallReason + " " + `Error creating ServiceInstanceCredential "test-binding/test-ns" for ServiceInstance "test-ns/test-instance" of ServiceClass "test-serviceclass" at ClusterServiceBroker "test-broker": Unexpected action`,
 	}
-	if e, a := expectedEvent, events[0]; e != a {
-		t.Fatalf("Received unexpected event: %v, expecting: %v", a, e)
+	if err := checkEvents(events, expectedEvents); err != nil {
+		t.Fatal(err)
 	}
 }
 
@@ -1238,29 +1277,42 @@ func TestReconcileServiceInstanceCredentialWithClusterServiceBrokerHTTPError(t *
 		},
 	}
 
+	// First iteration just records the start of the Bind and returns,
+	// without calling the broker.
 	err := testController.reconcileServiceInstanceCredential(binding)
 	if err != nil {
-		t.Fatal("reconcileServiceInstanceCredential should not have returned an error")
+		t.Fatalf("recording the start of the operation should not fail: %v", err)
 	}
 
 	actions := fakeCatalogClient.Actions()
-	assertNumberOfActions(t, actions, 2)
+	assertNumberOfActions(t, actions, 1)
 
 	updatedServiceInstanceCredential := assertUpdateStatus(t, actions[0], binding)
 	assertServiceInstanceCredentialOperationInProgress(t, updatedServiceInstanceCredential, v1alpha1.ServiceInstanceCredentialOperationBind, binding)
 	assertServiceInstanceCredentialOrphanMitigationSet(t, updatedServiceInstanceCredential, false)
 
-	updatedServiceInstanceCredential = assertUpdateStatus(t, actions[1], binding)
-	assertServiceInstanceCredentialRequestFailingError(t, updatedServiceInstanceCredential, v1alpha1.ServiceInstanceCredentialOperationBind, errorBindCallReason, "ServiceInstanceCredentialReturnedFailure", binding)
+	// Second iteration, fed the object the first call wrote back, issues
+	// the Bind that fails with the broker's HTTP error.
+	err = testController.reconcileServiceInstanceCredential(updatedServiceInstanceCredential.(*v1alpha1.ServiceInstanceCredential))
+	if err != nil {
+		t.Fatal("reconcileServiceInstanceCredential should not have returned an error")
+	}
+
+	actions = fakeCatalogClient.Actions()
+	assertNumberOfActions(t, actions, 2)
+
+	updatedServiceInstanceCredential = assertUpdateStatus(t, actions[1], updatedServiceInstanceCredential)
+	assertServiceInstanceCredentialRequestFailingError(t, updatedServiceInstanceCredential, v1alpha1.ServiceInstanceCredentialOperationBind, errorBindCallReason, errorBindCallReason, binding)
 	assertServiceInstanceCredentialOrphanMitigationSet(t, updatedServiceInstanceCredential, false)
 
 	events := getRecordedEvents(testController)
-	expectedEvent := apiv1.EventTypeWarning + " " + errorBindCallReason + " " + `Error creating ServiceInstanceCredential "test-binding/test-ns" for ServiceInstance "test-ns/test-instance" of ServiceClass "test-serviceclass" at ClusterServiceBroker "test-broker", Status: 422; ErrorMessage: AsyncRequired; Description: This service plan requires client support for asynchronous service operations.; ResponseError: <nil>`
-	if 1 != len(events) {
-		t.Fatalf("Did not record expected event, expecting: %v", expectedEvent)
+	expectedEvents := []string{
+		apiv1.EventTypeNormal + " " + successBindingStartedReason,
+		apiv1.EventTypeWarning + " " + errorBindCallReason + " " + `Error creating ServiceInstanceCredential "test-binding/test-ns" for ServiceInstance "test-ns/test-instance" of ServiceClass "test-serviceclass" at ClusterServiceBroker "test-broker", Status: 422; ErrorMessage: AsyncRequired; Description: This service plan requires client support for asynchronous service operations.; ResponseError: <nil>`,
+		apiv1.EventTypeWarning + " " + errorBindingFailedReason,
 	}
-	if e, a := expectedEvent, events[0]; e != a {
-		t.Fatalf("Received unexpected event: '%v', expecting: '%v'", a, e)
+	if err := checkEvents(events, expectedEvents); err != nil {
+		t.Fatal(err)
 	}
 }
 
@@ -1309,7 +1361,22 @@ func TestReconcileServiceInstanceCredentialWithServiceInstanceCredentialCallFail
 
 	binding := getTestServiceInstanceCredential()
 
-	if err := testController.reconcileServiceInstanceCredential(binding); err == nil {
+	// First iteration just records the start of the Bind and returns,
+	// without calling the broker or the kube client.
+	if err := testController.reconcileServiceInstanceCredential(binding); err != nil {
+		t.Fatalf("recording the start of the operation should not fail: %v", err)
+	}
+
+	actions := fakeCatalogClient.Actions()
+	assertNumberOfActions(t, actions, 1)
+
+	updatedServiceInstanceCredential := assertUpdateStatus(t, actions[0], binding)
+	assertServiceInstanceCredentialOperationInProgress(t, updatedServiceInstanceCredential, v1alpha1.ServiceInstanceCredentialOperationBind, binding)
+	assertServiceInstanceCredentialOrphanMitigationSet(t, updatedServiceInstanceCredential, false)
+
+	// Second iteration, fed the object the first call wrote back, issues
+	// the failing Bind.
+	if err := testController.reconcileServiceInstanceCredential(updatedServiceInstanceCredential.(*v1alpha1.ServiceInstanceCredential)); err == nil {
 		t.Fatal("ServiceInstanceCredential creation should fail")
 	}
 
@@ -1321,14 +1388,10 @@ func TestReconcileServiceInstanceCredentialWithServiceInstanceCredentialCallFail
 		t.Fatal(err)
 	}
 
-	actions := fakeCatalogClient.Actions()
+	actions = fakeCatalogClient.Actions()
 	assertNumberOfActions(t, actions, 2)
 
-	updatedServiceInstanceCredential := assertUpdateStatus(t, actions[0], binding)
-	assertServiceInstanceCredentialOperationInProgress(t, updatedServiceInstanceCredential, v1alpha1.ServiceInstanceCredentialOperationBind, binding)
-	assertServiceInstanceCredentialOrphanMitigationSet(t, updatedServiceInstanceCredential, false)
-
-	updatedServiceInstanceCredential = assertUpdateStatus(t, actions[1], binding)
+	updatedServiceInstanceCredential = assertUpdateStatus(t, actions[1], updatedServiceInstanceCredential)
 	assertServiceInstanceCredentialRequestRetriableError(t, updatedServiceInstanceCredential, v1alpha1.ServiceInstanceCredentialOperationBind, errorBindCallReason, binding)
 	assertServiceInstanceCredentialOrphanMitigationSet(t, updatedServiceInstanceCredential, false)
 
@@ -1346,12 +1409,12 @@ func TestReconcileServiceInstanceCredentialWithServiceInstanceCredentialCallFail
 	})
 
 	events := getRecordedEvents(testController)
-	assertNumEvents(t, events, 1)
-
-	expectedEvent := apiv1.EventTypeWarning + " " + errorBindCallReason + " " + "Error creating ServiceInstanceCredential \"test-binding/test-ns\" for ServiceInstance \"test-ns/test-instance\" of ServiceClass \"test-serviceclass\" at ClusterServiceBroker \"test-broker\": fake creation failure"
-
-	if e, a := expectedEvent, events[0]; e != a {
-		t.Fatalf("Received unexpected event: %v", a)
+	expectedEvents := []string{
+		apiv1.EventTypeNormal + " " + successBindingStartedReason,
+		apiv1.EventTypeWarning + " " + errorBindCallReason + " " + "Error creating ServiceInstanceCredential \"test-binding/test-ns\" for ServiceInstance \"test-ns/test-instance\" of ServiceClass \"test-serviceclass\" at ClusterServiceBroker \"test-broker\": fake creation failure",
+	}
+	if err := checkEvents(events, expectedEvents); err != nil {
+		t.Fatal(err)
 	}
 }
 
@@ -1375,7 +1438,22 @@ func TestReconcileServiceInstanceCredentialWithServiceInstanceCredentialFailure(
 
 	binding := getTestServiceInstanceCredential()
 
+	// First iteration just records the start of the Bind and returns,
+	// without calling the broker or the kube client.
 	if err := testController.reconcileServiceInstanceCredential(binding); err != nil {
+		t.Fatalf("recording the start of the operation should not fail: %v", err)
+	}
+
+	actions := fakeCatalogClient.Actions()
+	assertNumberOfActions(t, actions, 1)
+
+	updatedServiceInstanceCredential := assertUpdateStatus(t, actions[0], binding)
+	assertServiceInstanceCredentialOperationInProgress(t, updatedServiceInstanceCredential, v1alpha1.ServiceInstanceCredentialOperationBind, binding)
+	assertServiceInstanceCredentialOrphanMitigationSet(t, updatedServiceInstanceCredential, false)
+
+	// Second iteration, fed the object the first call wrote back, issues
+	// the Bind that the broker rejects.
+	if err := testController.reconcileServiceInstanceCredential(updatedServiceInstanceCredential.(*v1alpha1.ServiceInstanceCredential)); err != nil {
 		t.Fatalf("ServiceInstanceCredential creation should complete: %v", err)
 	}
 
@@ -1387,15 +1465,11 @@ func TestReconcileServiceInstanceCredentialWithServiceInstanceCredentialFailure(
 		t.Fatal(err)
 	}
 
-	actions := fakeCatalogClient.Actions()
+	actions = fakeCatalogClient.Actions()
 	assertNumberOfActions(t, actions, 2)
 
-	updatedServiceInstanceCredential := assertUpdateStatus(t, actions[0], binding)
-	assertServiceInstanceCredentialOperationInProgress(t, updatedServiceInstanceCredential, v1alpha1.ServiceInstanceCredentialOperationBind, binding)
-	assertServiceInstanceCredentialOrphanMitigationSet(t, updatedServiceInstanceCredential, false)
-
-	updatedServiceInstanceCredential = assertUpdateStatus(t, actions[1], binding)
-	assertServiceInstanceCredentialRequestFailingError(t, updatedServiceInstanceCredential, v1alpha1.ServiceInstanceCredentialOperationBind, errorBindCallReason, "ServiceInstanceCredentialReturnedFailure", binding)
+	updatedServiceInstanceCredential = assertUpdateStatus(t, actions[1], updatedServiceInstanceCredential)
+	assertServiceInstanceCredentialRequestFailingError(t, updatedServiceInstanceCredential, v1alpha1.ServiceInstanceCredentialOperationBind, errorBindCallReason, errorBindCallReason, binding)
 	assertServiceInstanceCredentialOrphanMitigationSet(t, updatedServiceInstanceCredential, false)
 
 	brokerActions := fakeClusterServiceBrokerClient.Actions()
@@ -1412,12 +1486,13 @@ func TestReconcileServiceInstanceCredentialWithServiceInstanceCredentialFailure(
 	})
 
 	events := getRecordedEvents(testController)
-	assertNumEvents(t, events, 1)
-
-	expectedEvent := apiv1.EventTypeWarning + " " + errorBindCallReason + " " + "Error creating ServiceInstanceCredential \"test-binding/test-ns\" for ServiceInstance \"test-ns/test-instance\" of ServiceClass \"test-serviceclass\" at ClusterServiceBroker \"test-broker\", Status: 409; ErrorMessage: ServiceInstanceCredentialExists; Description: Service binding with the same id, for the same service instance already exists.; ResponseError: <nil>"
-
-	if e, a := expectedEvent, events[0]; e != a {
-		t.Fatalf("Received unexpected event: %v", a)
+	expectedEvents := []string{
+		apiv1.EventTypeNormal + " " + successBindingStartedReason,
+		apiv1.EventTypeWarning + " " + errorBindCallReason + " " + "Error creating ServiceInstanceCredential \"test-binding/test-ns\" for ServiceInstance \"test-ns/test-instance\" of ServiceClass \"test-serviceclass\" at ClusterServiceBroker \"test-broker\", Status: 409; ErrorMessage: ServiceInstanceCredentialExists; Description: Service binding with the same id, for the same service instance already exists.; ResponseError: <nil>",
+		apiv1.EventTypeWarning + " " + errorBindingFailedReason,
+	}
+	if err := checkEvents(events, expectedEvents); err != nil {
+		t.Fatal(err)
 	}
 }
 
@@ -1609,28 +1684,40 @@ func TestReconcileUnbindingWithClusterServiceBrokerError(t *testing.T) {
 	if err := scmeta.AddFinalizer(binding, v1alpha1.FinalizerServiceCatalog); err != nil {
 		t.Fatalf("Finalizer error: %v", err)
 	}
-	if err := testController.reconcileServiceInstanceCredential(binding); err == nil {
-		t.Fatal("reconcileServiceInstanceCredential should have returned an error")
+
+	// First iteration just records the start of the Unbind and returns,
+	// without calling the broker.
+	if err := testController.reconcileServiceInstanceCredential(binding); err != nil {
+		t.Fatalf("recording the start of the operation should not fail: %v", err)
 	}
 
 	actions := fakeCatalogClient.Actions()
-	assertNumberOfActions(t, actions, 2)
+	assertNumberOfActions(t, actions, 1)
 
 	updatedServiceInstanceCredential := assertUpdateStatus(t, actions[0], binding)
 	assertServiceInstanceCredentialOperationInProgress(t, updatedServiceInstanceCredential, v1alpha1.ServiceInstanceCredentialOperationUnbind, binding)
 	assertServiceInstanceCredentialOrphanMitigationSet(t, updatedServiceInstanceCredential, false)
 
-	updatedServiceInstanceCredential = assertUpdateStatus(t, actions[1], binding)
+	// Second iteration, fed the object the first call wrote back, issues
+	// the failing Unbind.
+	if err := testController.reconcileServiceInstanceCredential(updatedServiceInstanceCredential.(*v1alpha1.ServiceInstanceCredential)); err == nil {
+		t.Fatal("reconcileServiceInstanceCredential should have returned an error")
+	}
+
+	actions = fakeCatalogClient.Actions()
+	assertNumberOfActions(t, actions, 2)
+
+	updatedServiceInstanceCredential = assertUpdateStatus(t, actions[1], updatedServiceInstanceCredential)
 	assertServiceInstanceCredentialRequestRetriableError(t, updatedServiceInstanceCredential, v1alpha1.ServiceInstanceCredentialOperationUnbind, errorUnbindCallReason, binding)
 	assertServiceInstanceCredentialOrphanMitigationSet(t, updatedServiceInstanceCredential, false)
 
 	events := getRecordedEvents(testController)
-	expectedEvent := apiv1.EventTypeWarning + " " + errorUnbindCallReason + " " + `Error unbinding ServiceInstanceCredential "test-ns/test-binding" for ServiceInstance "test-ns/test-instance" of ServiceClass "test-serviceclass" at ClusterServiceBroker "test-broker": Unexpected action`
-	if 1 != len(events) {
-		t.Fatalf("Did not record expected event, expecting: %v", expectedEvent)
+	expectedEvents := []string{
+		apiv1.EventTypeNormal + " " + successBindingStartedReason,
+		apiv1.EventTypeWarning + " " + errorUnbindCallReason + " " + `Error unbinding ServiceInstanceCredential "test-ns/test-binding" for ServiceInstance "test-ns/test-instance" of ServiceClass "test-serviceclass" at ClusterServiceBroker "test-broker": Unexpected action`,
 	}
-	if e, a := expectedEvent, events[0]; e != a {
-		t.Fatalf("Received unexpected event: %v, expecting: %v", a, e)
+	if err := checkEvents(events, expectedEvents); err != nil {
+		t.Fatal(err)
 	}
 }
 
@@ -1672,29 +1759,40 @@ func TestReconcileUnbindingWithClusterServiceBrokerHTTPError(t *testing.T) {
 	if err := scmeta.AddFinalizer(binding, v1alpha1.FinalizerServiceCatalog); err != nil {
 		t.Fatalf("Finalizer error: %v", err)
 	}
+
+	// First iteration just records the start of the Unbind and returns,
+	// without calling the broker.
 	if err := testController.reconcileServiceInstanceCredential(binding); err != nil {
-		t.Fatalf("reconcileServiceInstanceCredential should not have returned an error: %v", err)
+		t.Fatalf("recording the start of the operation should not fail: %v", err)
 	}
 
 	actions := fakeCatalogClient.Actions()
-	assertNumberOfActions(t, actions, 2)
+	assertNumberOfActions(t, actions, 1)
 
 	updatedServiceInstanceCredential := assertUpdateStatus(t, actions[0], binding)
 	assertServiceInstanceCredentialOperationInProgress(t, updatedServiceInstanceCredential, v1alpha1.ServiceInstanceCredentialOperationUnbind, binding)
 	assertServiceInstanceCredentialOrphanMitigationSet(t, updatedServiceInstanceCredential, false)
 
-	updatedServiceInstanceCredential = assertUpdateStatus(t, actions[1], binding)
+	// Second iteration, fed the object the first call wrote back, issues
+	// the Unbind that fails with the broker's HTTP error.
+	if err := testController.reconcileServiceInstanceCredential(updatedServiceInstanceCredential.(*v1alpha1.ServiceInstanceCredential)); err != nil {
+		t.Fatalf("reconcileServiceInstanceCredential should not have returned an error: %v", err)
+	}
+
+	actions = fakeCatalogClient.Actions()
+	assertNumberOfActions(t, actions, 2)
+
+	updatedServiceInstanceCredential = assertUpdateStatus(t, actions[1], updatedServiceInstanceCredential)
 	assertServiceInstanceCredentialRequestFailingError(t, updatedServiceInstanceCredential, v1alpha1.ServiceInstanceCredentialOperationUnbind, errorUnbindCallReason, errorUnbindCallReason, binding)
 	assertServiceInstanceCredentialOrphanMitigationSet(t, updatedServiceInstanceCredential, false)
 
 	events := getRecordedEvents(testController)
-
-	expectedEvent := apiv1.EventTypeWarning + " " + errorUnbindCallReason + " " + `Error unbinding ServiceInstanceCredential "test-binding/test-ns" for ServiceInstance "test-ns/test-instance" of ServiceClass "test-serviceclass" at ClusterServiceBroker "test-broker": Status: 410; ErrorMessage: <nil>; Description: <nil>; ResponseError: <nil>`
-	if 1 != len(events) {
-		t.Fatalf("Did not record expected event, expecting: %v", expectedEvent)
+	expectedEvents := []string{
+		apiv1.EventTypeNormal + " " + successBindingStartedReason,
+		apiv1.EventTypeWarning + " " + errorUnbindCallReason + " " + `Error unbinding ServiceInstanceCredential "test-binding/test-ns" for ServiceInstance "test-ns/test-instance" of ServiceClass "test-serviceclass" at ClusterServiceBroker "test-broker": Status: 410; ErrorMessage: <nil>; Description: <nil>; ResponseError: <nil>`,
 	}
-	if e, a := expectedEvent, events[0]; e != a {
-		t.Fatalf("Received unexpected event: %v, expecting: %v", a, e)
+	if err := checkEvents(events, expectedEvents); err != nil {
+		t.Fatal(err)
 	}
 }
 
@@ -1725,6 +1823,13 @@ func TestReconcileBindingUsingOriginatingIdentity(t *testing.T) {
 				binding.Spec.UserInfo = testUserInfo
 			}
 
+			// First call only records the start of the Bind operation; the
+			// broker isn't called until the second.
+			if err := testController.reconcileServiceInstanceCredential(binding); err != nil {
+				t.Fatalf("%v: recording the start of the operation should not fail: %v", tc.name, err)
+			}
+			binding.Status.CurrentOperation = v1alpha1.ServiceInstanceCredentialOperationBind
+
 			err := testController.reconcileServiceInstanceCredential(binding)
 			if err != nil {
 				t.Fatalf("%v: a valid binding should not fail: %v", tc.name, err)
@@ -1857,8 +1962,9 @@ func TestReconcileBindingSuccessOnFinalRetry(t *testing.T) {
 	}
 }
 
-// TestReconcileBindingFailureOnFinalRetry verifies that reconciliation
-// completes in the event of an error after the retry duration elapses.
+// TestReconcileBindingFailureOnFinalRetry verifies that once the retry
+// duration has already elapsed on entry, reconciliation fails the binding on
+// the timeout alone, without attempting another Bind call.
 func TestReconcileBindingFailureOnFinalRetry(t *testing.T) {
 	_, fakeCatalogClient, _, testController, sharedInformers := newTestController(t, fakeosb.FakeClientConfiguration{
 		BindReaction: &fakeosb.BindReaction{
@@ -1890,27 +1996,23 @@ func TestReconcileBindingFailureOnFinalRetry(t *testing.T) {
 	assertNumberOfActions(t, actions, 1)
 
 	updatedServiceInstanceCredential := assertUpdateStatus(t, actions[0], binding).(*v1alpha1.ServiceInstanceCredential)
-	assertServiceInstanceCredentialRequestFailingError(t, updatedServiceInstanceCredential, v1alpha1.ServiceInstanceCredentialOperationBind, errorBindCallReason, errorReconciliationRetryTimeoutReason, binding)
+	assertServiceInstanceCredentialRequestFailingError(t, updatedServiceInstanceCredential, v1alpha1.ServiceInstanceCredentialOperationBind, errorReconciliationRetryTimeoutReason, errorReconciliationRetryTimeoutReason, binding)
 	assertServiceInstanceCredentialOrphanMitigationSet(t, updatedServiceInstanceCredential, false)
 
 	expectedEventPrefixes := []string{
-		apiv1.EventTypeWarning + " " + errorBindCallReason,
 		apiv1.EventTypeWarning + " " + errorReconciliationRetryTimeoutReason,
 	}
 	events := getRecordedEvents(testController)
-	assertNumEvents(t, events, len(expectedEventPrefixes))
-
-	for i, e := range expectedEventPrefixes {
-		a := events[i]
-		if !strings.HasPrefix(a, e) {
-			t.Fatalf("Received unexpected event:\n  expected prefix: %v\n  got: %v", e, a)
-		}
+	if err := checkEvents(events, expectedEventPrefixes); err != nil {
+		t.Fatal(err)
 	}
 }
 
-// TestReconcileBindingWithSecretConflictFailedAfterFinalRetry tests
-// reconcileBinding to ensure a binding with an existing secret not owned by the
-// bindings is marked as failed after the retry duration elapses.
+// TestReconcileBindingWithSecretConflictFailedAfterFinalRetry tests that once
+// the retry duration has already elapsed on entry, reconciliation fails the
+// binding on the timeout alone: the secret conflict that would otherwise
+// trigger orphan mitigation is never reached, because the broker is never
+// called.
 func TestReconcileBindingWithSecretConflictFailedAfterFinalRetry(t *testing.T) {
 	fakeKubeClient, fakeCatalogClient, fakeClusterServiceBrokerClient, testController, sharedInformers := newTestController(t, fakeosb.FakeClientConfiguration{
 		BindReaction: &fakeosb.BindReaction{
@@ -1957,57 +2059,33 @@ func TestReconcileBindingWithSecretConflictFailedAfterFinalRetry(t *testing.T) {
 	}
 
 	brokerActions := fakeClusterServiceBrokerClient.Actions()
-	assertNumberOfClusterServiceBrokerActions(t, brokerActions, 1)
-	assertBind(t, brokerActions[0], &osb.BindRequest{
-		BindingID:  bindingGUID,
-		InstanceID: instanceGUID,
-		ServiceID:  serviceClassGUID,
-		PlanID:     planGUID,
-		AppGUID:    strPtr(testNsUID),
-		BindResource: &osb.BindResource{
-			AppGUID: strPtr(testNsUID),
-		},
-	})
+	assertNumberOfClusterServiceBrokerActions(t, brokerActions, 0)
 
 	actions := fakeCatalogClient.Actions()
 	assertNumberOfActions(t, actions, 1)
 
 	updatedServiceInstanceCredential := assertUpdateStatus(t, actions[0], binding).(*v1alpha1.ServiceInstanceCredential)
-	assertServiceInstanceCredentialReadyFalse(t, updatedServiceInstanceCredential, errorServiceInstanceCredentialOrphanMitigation)
+	assertServiceInstanceCredentialReadyFalse(t, updatedServiceInstanceCredential, errorReconciliationRetryTimeoutReason)
 	assertServiceInstanceCredentialCondition(t, updatedServiceInstanceCredential, v1alpha1.ServiceInstanceCredentialConditionFailed, v1alpha1.ConditionTrue, errorReconciliationRetryTimeoutReason)
-	assertServiceInstanceCredentialCurrentOperation(t, updatedServiceInstanceCredential, v1alpha1.ServiceInstanceCredentialOperationBind)
+	assertServiceInstanceCredentialCurrentOperation(t, updatedServiceInstanceCredential, "")
 	assertServiceInstanceCredentialOperationStartTimeSet(t, updatedServiceInstanceCredential, false)
-	assertServiceInstanceCredentialReconciledGeneration(t, updatedServiceInstanceCredential, binding.Status.ReconciledGeneration)
+	assertServiceInstanceCredentialReconciledGeneration(t, updatedServiceInstanceCredential, binding.Generation)
 	assertServiceInstanceCredentialInProgressPropertiesNil(t, updatedServiceInstanceCredential)
-	// External properties are updated because the bind request with the Broker was successful
 	assertServiceInstanceCredentialExternalPropertiesParameters(t, updatedServiceInstanceCredential, nil, "")
-	assertServiceInstanceCredentialCondition(t, updatedServiceInstanceCredential, v1alpha1.ServiceInstanceCredentialConditionReady, v1alpha1.ConditionFalse, errorServiceInstanceCredentialOrphanMitigation)
-	assertServiceInstanceCredentialOrphanMitigationSet(t, updatedServiceInstanceCredential, true)
+	assertServiceInstanceCredentialOrphanMitigationSet(t, updatedServiceInstanceCredential, false)
 
+	// The namespace/secret gets never happen: the timeout check runs before
+	// the broker (and therefore before the secret-conflict check) is ever
+	// reached.
 	kubeActions := fakeKubeClient.Actions()
-	assertNumberOfActions(t, kubeActions, 2)
-
-	// first action is a get on the namespace
-	// second action is a get on the secret
-	action := kubeActions[1].(clientgotesting.GetAction)
-	if e, a := "get", action.GetVerb(); e != a {
-		t.Fatalf("Unexpected verb on action; expected %v, got %v", e, a)
-	}
-	if e, a := "secrets", action.GetResource().Resource; e != a {
-		t.Fatalf("Unexpected resource on action; expected %v, got %v", e, a)
-	}
+	assertNumberOfActions(t, kubeActions, 0)
 
 	expectedEventPrefixes := []string{
-		apiv1.EventTypeWarning + " " + errorInjectingBindResultReason,
 		apiv1.EventTypeWarning + " " + errorReconciliationRetryTimeoutReason,
-		apiv1.EventTypeWarning + " " + errorServiceInstanceCredentialOrphanMitigation,
 	}
 	events := getRecordedEvents(testController)
-	assertNumEvents(t, events, len(expectedEventPrefixes))
-	for i, e := range expectedEventPrefixes {
-		if a := events[i]; !strings.HasPrefix(a, e) {
-			t.Fatalf("Received unexpected event:\n  expected prefix: %v\n  got: %v", e, a)
-		}
+	if err := checkEvents(events, expectedEventPrefixes); err != nil {
+		t.Fatal(err)
 	}
 }
 
@@ -2054,6 +2132,67 @@ func TestReconcileServiceInstanceCredentialWithStatusUpdateError(t *testing.T) {
 	assertNumEvents(t, events, 0)
 }
 
+// TestReconcileServiceInstanceCredentialWithStatusUpdateErrorAfterBind is a
+// regression test for the race this chunk's reconcile split guards against:
+// a conflict on the terminal UpdateStatus that follows a successful Bind
+// must not cause that Bind to be issued more than once within the same
+// reconcile call.
+func TestReconcileServiceInstanceCredentialWithStatusUpdateErrorAfterBind(t *testing.T) {
+	fakeKubeClient, fakeCatalogClient, fakeClusterServiceBrokerClient, testController, sharedInformers := newTestController(t, fakeosb.FakeClientConfiguration{
+		BindReaction: &fakeosb.BindReaction{
+			Response: &osb.BindResponse{
+				Credentials: map[string]interface{}{
+					"a": "b",
+				},
+			},
+		},
+	})
+
+	addGetNamespaceReaction(fakeKubeClient)
+	addGetSecretNotFoundReaction(fakeKubeClient)
+
+	sharedInformers.ClusterServiceBrokers().Informer().GetStore().Add(getTestClusterServiceBroker())
+	sharedInformers.ServiceClasses().Informer().GetStore().Add(getTestServiceClass())
+	sharedInformers.ServicePlans().Informer().GetStore().Add(getTestServicePlan())
+	sharedInformers.ServiceInstances().Informer().GetStore().Add(getTestServiceInstanceWithStatus(v1alpha1.ConditionTrue))
+
+	binding := getTestServiceInstanceCredential()
+
+	// First call only records the start of the Bind operation.
+	if err := testController.reconcileServiceInstanceCredential(binding); err != nil {
+		t.Fatalf("recording the start of the operation should not fail: %v", err)
+	}
+
+	actions := fakeCatalogClient.Actions()
+	assertNumberOfActions(t, actions, 1)
+	updatedServiceInstanceCredential2 := assertUpdateStatus(t, actions[0], binding).(*v1alpha1.ServiceInstanceCredential)
+
+	// The second call performs the actual Bind, which succeeds, but the
+	// terminal status update that records its success conflicts -- as if
+	// something else had updated the binding's resourceVersion in between.
+	gr := schema.GroupResource{Group: "servicecatalog.k8s.io", Resource: "serviceinstancecredentials"}
+	fakeCatalogClient.AddReactor("update", "serviceinstancecredentials", func(action clientgotesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewConflict(gr, binding.Name, errors.New("update conflict"))
+	})
+
+	if err := testController.reconcileServiceInstanceCredential(updatedServiceInstanceCredential2); err == nil {
+		t.Fatalf("expected a conflict error from the terminal status update, got none")
+	}
+
+	brokerActions := fakeClusterServiceBrokerClient.Actions()
+	assertNumberOfClusterServiceBrokerActions(t, brokerActions, 1)
+	assertBind(t, brokerActions[0], &osb.BindRequest{
+		BindingID:  bindingGUID,
+		InstanceID: instanceGUID,
+		ServiceID:  serviceClassGUID,
+		PlanID:     planGUID,
+		AppGUID:    strPtr(testNsUID),
+		BindResource: &osb.BindResource{
+			AppGUID: strPtr(testNsUID),
+		},
+	})
+}
+
 // TestReconcileServiceInstanceCredentailWithSecretParameters tests reconciling a
 // binding that has parameters obtained from secrets.
 func TestReconcileServiceInstanceCredentialWithSecretParameters(t *testing.T) {
@@ -2120,7 +2259,32 @@ func TestReconcileServiceInstanceCredentialWithSecretParameters(t *testing.T) {
 		},
 	}
 
-	err = testController.reconcileServiceInstanceCredential(binding)
+	// First call only records the start of the Bind operation and returns;
+	// the broker isn't called until the second.
+	if err := testController.reconcileServiceInstanceCredential(binding); err != nil {
+		t.Fatalf("recording the start of the operation should not fail: %v", err)
+	}
+
+	actions := fakeCatalogClient.Actions()
+	assertNumberOfActions(t, actions, 1)
+
+	expectedParameters := map[string]interface{}{
+		"a": "1",
+		"b": "<redacted>",
+	}
+	expectedParametersChecksum, err := generateChecksumOfParameters(map[string]interface{}{
+		"a": "1",
+		"b": "2",
+	})
+	if err != nil {
+		t.Fatalf("Failed to generate parameters checksum: %v", err)
+	}
+
+	updatedServiceInstanceCredential := assertUpdateStatus(t, actions[0], binding)
+	assertServiceInstanceCredentialOperationInProgressWithParameters(t, updatedServiceInstanceCredential, v1alpha1.ServiceInstanceCredentialOperationBind, expectedParameters, expectedParametersChecksum, binding)
+	assertServiceInstanceCredentialOrphanMitigationSet(t, updatedServiceInstanceCredential, false)
+
+	err = testController.reconcileServiceInstanceCredential(updatedServiceInstanceCredential.(*v1alpha1.ServiceInstanceCredential))
 	if err != nil {
 		t.Fatalf("a valid binding should not fail: %v", err)
 	}
@@ -2142,26 +2306,10 @@ func TestReconcileServiceInstanceCredentialWithSecretParameters(t *testing.T) {
 		},
 	})
 
-	expectedParameters := map[string]interface{}{
-		"a": "1",
-		"b": "<redacted>",
-	}
-	expectedParametersChecksum, err := generateChecksumOfParameters(map[string]interface{}{
-		"a": "1",
-		"b": "2",
-	})
-	if err != nil {
-		t.Fatalf("Failed to generate parameters checksum: %v", err)
-	}
-
-	actions := fakeCatalogClient.Actions()
+	actions = fakeCatalogClient.Actions()
 	assertNumberOfActions(t, actions, 2)
 
-	updatedServiceInstanceCredential := assertUpdateStatus(t, actions[0], binding)
-	assertServiceInstanceCredentialOperationInProgressWithParameters(t, updatedServiceInstanceCredential, v1alpha1.ServiceInstanceCredentialOperationBind, expectedParameters, expectedParametersChecksum, binding)
-	assertServiceInstanceCredentialOrphanMitigationSet(t, updatedServiceInstanceCredential, false)
-
-	updatedServiceInstanceCredential = assertUpdateStatus(t, actions[1], binding)
+	updatedServiceInstanceCredential = assertUpdateStatus(t, actions[1], updatedServiceInstanceCredential)
 	assertServiceInstanceCredentialOperationSuccessWithParameters(t, updatedServiceInstanceCredential, v1alpha1.ServiceInstanceCredentialOperationBind, expectedParameters, expectedParametersChecksum, binding)
 	assertServiceInstanceCredentialOrphanMitigationSet(t, updatedServiceInstanceCredential, false)
 
@@ -2290,6 +2438,14 @@ func TestReconcileBindingWithSetOrphanMitigation(t *testing.T) {
 				SecretName:         testServiceInstanceCredentialSecretName,
 			},
 		}
+		// Seed the binding as already mid-Bind (rather than calling
+		// reconcile an extra time just to get it there), matching how the
+		// other final-retry tests in this file drive a single reconcile
+		// call straight to reconcileServiceInstanceCredentialPoll: each
+		// reconcile invocation performs at most one status update, so a
+		// fresh binding's first call would only record the start of the
+		// operation and return before ever calling Bind.
+		binding.Status.CurrentOperation = v1alpha1.ServiceInstanceCredentialOperationBind
 		startTime := metav1.NewTime(time.Now().Add(-7 * 24 * time.Hour))
 		binding.Status.OperationStartTime = &startTime
 
@@ -2321,19 +2477,83 @@ func TestReconcileBindingWithSetOrphanMitigation(t *testing.T) {
 		}
 
 		actions := fakeCatalogClient.Actions()
-		assertNumberOfActions(t, actions, 2)
+		assertNumberOfActions(t, actions, 1)
 
 		updatedServiceInstanceCredential := assertUpdateStatus(t, actions[0], binding).(*v1alpha1.ServiceInstanceCredential)
 		assertServiceInstanceCredentialReadyFalse(t, updatedServiceInstanceCredential)
-
-		updatedServiceInstanceCredential = assertUpdateStatus(t, actions[1], binding).(*v1alpha1.ServiceInstanceCredential)
-		assertServiceInstanceCredentialReadyFalse(t, updatedServiceInstanceCredential)
 		assertServiceInstanceCredentialCondition(t, updatedServiceInstanceCredential, v1alpha1.ServiceInstanceCredentialConditionReady, v1alpha1.ConditionFalse)
 
 		assertServiceInstanceCredentialOrphanMitigationSet(t, updatedServiceInstanceCredential, tc.setOrphanMitigation)
 	}
 }
 
+// TestReconcileBindingWithDeletedServiceClassOrServicePlan tests
+// reconcileServiceInstanceCredential to ensure a binding whose
+// ClusterServiceClass or ClusterServicePlan is being deleted is rejected
+// without ever calling the broker, and without triggering orphan
+// mitigation (since no Bind was sent).
+func TestReconcileBindingWithDeletedServiceClassOrServicePlan(t *testing.T) {
+	cases := []struct {
+		name           string
+		deleteClass    bool
+		deletePlan     bool
+		expectedReason string
+	}{
+		{
+			name:           "deleted service class",
+			deleteClass:    true,
+			expectedReason: errorDeletedClusterServiceClassReason,
+		},
+		{
+			name:           "deleted service plan",
+			deletePlan:     true,
+			expectedReason: errorDeletedClusterServicePlanReason,
+		},
+	}
+
+	for _, tc := range cases {
+		_, fakeCatalogClient, fakeClusterServiceBrokerClient, testController, sharedInformers := newTestController(t, noFakeActions())
+
+		sharedInformers.ClusterServiceBrokers().Informer().GetStore().Add(getTestClusterServiceBroker())
+
+		serviceClass := getTestServiceClass()
+		if tc.deleteClass {
+			serviceClass.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+		}
+		sharedInformers.ServiceClasses().Informer().GetStore().Add(serviceClass)
+
+		servicePlan := getTestServicePlan()
+		if tc.deletePlan {
+			servicePlan.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+		}
+		sharedInformers.ServicePlans().Informer().GetStore().Add(servicePlan)
+
+		sharedInformers.ServiceInstances().Informer().GetStore().Add(getTestServiceInstanceWithStatus(v1alpha1.ConditionTrue))
+
+		binding := getTestServiceInstanceCredential()
+
+		if err := testController.reconcileServiceInstanceCredential(binding); err != nil {
+			t.Fatalf("%s: unexpected error from reconciliation: %v", tc.name, err)
+		}
+
+		brokerActions := fakeClusterServiceBrokerClient.Actions()
+		assertNumberOfClusterServiceBrokerActions(t, brokerActions, 0)
+
+		actions := fakeCatalogClient.Actions()
+		assertNumberOfActions(t, actions, 1)
+
+		updatedServiceInstanceCredential := assertUpdateStatus(t, actions[0], binding).(*v1alpha1.ServiceInstanceCredential)
+		assertServiceInstanceCredentialReadyFalse(t, updatedServiceInstanceCredential, tc.expectedReason)
+		assertServiceInstanceCredentialOrphanMitigationSet(t, updatedServiceInstanceCredential, false)
+
+		events := getRecordedEvents(testController)
+		assertNumEvents(t, events, 1)
+		if e, a := apiv1.EventTypeWarning+" "+tc.expectedReason, events[0]; !strings.HasPrefix(a, e) {
+			t.Fatalf("%s: received unexpected event: %v", tc.name, a)
+		}
+	}
+}
+
 // TestReconcileBindingWithOrphanMitigationInProgress tests
 // reconcileServiceInstanceCredential to ensure a binding is properly handled
 // once orphan mitigation is underway.
@@ -0,0 +1,354 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+
+	osb "github.com/pmorie/go-open-service-broker-client/v2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilfeature "k8s.io/apiserver/pkg/util/feature"
+	"k8s.io/client-go/pkg/api/v1"
+
+	scfeatures "github.com/kubernetes-incubator/service-catalog/pkg/features"
+
+	"github.com/kubernetes-incubator/service-catalog/pkg/apis/servicecatalog/v1alpha1"
+)
+
+// v1alpha1SchemeGroupVersionKind identifies ServiceInstanceCredential for
+// building owner references on the Secrets it projects.
+var v1alpha1SchemeGroupVersionKind = schema.GroupVersionKind{
+	Group:   "servicecatalog.k8s.io",
+	Version: "v1alpha1",
+	Kind:    "ServiceInstanceCredential",
+}
+
+// getServiceClassPlanAndBrokerForInstance resolves the ClusterServiceClass,
+// ClusterServicePlan, and ClusterServiceBroker referenced by instance, and
+// constructs an osb.Client configured to talk to that broker.
+func (c *controller) getServiceClassPlanAndBrokerForInstance(instance *v1alpha1.ServiceInstance) (
+	*v1alpha1.ClusterServiceClass, *v1alpha1.ClusterServicePlan, *v1alpha1.ClusterServiceBroker, osb.Client, error,
+) {
+	serviceClass, err := c.serviceClassLister.Get(instance.Spec.ServiceClassRef.Name)
+	if err != nil {
+		return nil, nil, nil, nil, fmt.Errorf("ServiceClass %q not found: %v", instance.Spec.ExternalServiceClassName, err)
+	}
+	servicePlan, err := c.servicePlanLister.Get(instance.Spec.ServicePlanRef.Name)
+	if err != nil {
+		return nil, nil, nil, nil, fmt.Errorf("ServicePlan %q not found: %v", instance.Spec.ExternalServicePlanName, err)
+	}
+	broker, err := c.brokerLister.Get(serviceClass.Spec.ClusterServiceBrokerName)
+	if err != nil {
+		return nil, nil, nil, nil, fmt.Errorf("ClusterServiceBroker %q not found: %v", serviceClass.Spec.ClusterServiceBrokerName, err)
+	}
+	brokerClient, err := c.brokerClientCreateFunc(&osb.ClientConfiguration{
+		Name: broker.Name,
+		URL:  broker.Spec.URL,
+	})
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	return serviceClass, servicePlan, broker, brokerClient, nil
+}
+
+// buildParameters unmarshals raw (a ServiceInstanceCredentialSpec's
+// RawExtension) into a map suitable for an osb.BindRequest, and returns it
+// alongside the checksum of its JSON encoding.
+func buildParameters(raw *runtime.RawExtension) (map[string]interface{}, string, error) {
+	if raw == nil {
+		return nil, "", nil
+	}
+	var parameters map[string]interface{}
+	if err := json.Unmarshal(raw.Raw, &parameters); err != nil {
+		return nil, "", fmt.Errorf("failed to unmarshal parameters: %v", err)
+	}
+	checksum, err := generateChecksumOfParameters(parameters)
+	if err != nil {
+		return nil, "", err
+	}
+	return parameters, checksum, nil
+}
+
+// credentialsKey is the Secret key under which credentialsToSecretData
+// writes the entire broker Credentials object, JSON-marshaled, whenever
+// format is anything other than CredentialsFormatRaw. It lets
+// servicebinding.io-style consumers project the whole binding as a single
+// file instead of reassembling it from individual keys.
+const credentialsKey = "credentials"
+
+// credentialsToSecretData converts a broker's Bind response Credentials into
+// the byte-keyed map a Secret's Data expects, honoring format:
+//
+//   - CredentialsFormatRaw (the default, also used when format is empty)
+//     writes each value under its own key only: scalars stringified,
+//     nested objects and arrays JSON-marshaled.
+//   - CredentialsFormatJSON marshals object/array values to JSON bytes
+//     under the same key, with an accompanying "<key>.json" entry holding
+//     the same bytes, leaving scalars stringified as in Raw. It also adds a
+//     top-level "credentials" key with the whole object JSON-marshaled.
+//   - CredentialsFormatFlat does the same as JSON, and additionally
+//     projects nested object values as one key per leaf path (e.g.
+//     "tls.ca", "tls.cert") alongside the top-level JSON blob.
+func credentialsToSecretData(credentials map[string]interface{}, format v1alpha1.CredentialsFormat) (map[string][]byte, error) {
+	if format == "" {
+		format = v1alpha1.CredentialsFormatRaw
+	}
+	secretData := make(map[string][]byte, len(credentials))
+	for k, v := range credentials {
+		b, isStructured, err := credentialValueToBytes(v)
+		if err != nil {
+			return nil, fmt.Errorf("credential value for key %q could not be encoded: %v", k, err)
+		}
+		secretData[k] = b
+
+		if isStructured && format != v1alpha1.CredentialsFormatRaw {
+			secretData[k+".json"] = b
+		}
+		if isStructured && format == v1alpha1.CredentialsFormatFlat {
+			flattenInto(secretData, k, v)
+		}
+	}
+
+	if format != v1alpha1.CredentialsFormatRaw {
+		whole, err := json.Marshal(credentials)
+		if err != nil {
+			return nil, fmt.Errorf("credentials could not be encoded as a whole object: %v", err)
+		}
+		secretData[credentialsKey] = whole
+	}
+	return secretData, nil
+}
+
+// credentialValueToBytes renders a single credential value as bytes: strings
+// and byte slices are passed through, other scalars are formatted with
+// fmt.Sprint, and objects/arrays are JSON-marshaled. isStructured reports the
+// latter case, so callers can decide whether to also flatten it.
+func credentialValueToBytes(v interface{}) ([]byte, bool, error) {
+	switch val := v.(type) {
+	case string:
+		return []byte(val), false, nil
+	case []byte:
+		return val, false, nil
+	case map[string]interface{}, []interface{}:
+		b, err := json.Marshal(val)
+		return b, true, err
+	default:
+		return []byte(fmt.Sprint(val)), false, nil
+	}
+}
+
+// flattenInto projects the leaves of a nested credential value into
+// secretData, one entry per dotted path rooted at prefix (e.g. prefix "tls"
+// and a value of {"ca": "...", "cert": "..."} yields "tls.ca" and
+// "tls.cert").
+func flattenInto(secretData map[string][]byte, prefix string, v interface{}) {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for k, child := range val {
+			flattenInto(secretData, prefix+"."+k, child)
+		}
+	case []interface{}:
+		for i, child := range val {
+			flattenInto(secretData, fmt.Sprintf("%s.%d", prefix, i), child)
+		}
+	default:
+		b, _, _ := credentialValueToBytes(val)
+		secretData[prefix] = b
+	}
+}
+
+// recordStartOfServiceInstanceCredentialOperation records that the
+// controller is about to perform op against the broker, persisting an
+// in-progress status update before any broker RPC is made so that a crash
+// mid-bind is observable on the next reconcile. It also emits a Normal
+// BindingStarted event, giving every Bind and Unbind attempt a visible
+// starting point to pair against whatever Warning event (if any) eventually
+// reports its outcome.
+func (c *controller) recordStartOfServiceInstanceCredentialOperation(
+	binding *v1alpha1.ServiceInstanceCredential,
+	op v1alpha1.ServiceInstanceCredentialOperation,
+	parameters *runtime.RawExtension,
+	parametersChecksum string,
+) (*v1alpha1.ServiceInstanceCredential, error) {
+	clone := binding.DeepCopy()
+	clone.Status.CurrentOperation = op
+	now := metav1.Now()
+	clone.Status.OperationStartTime = &now
+	clone.Status.InProgressProperties = &v1alpha1.ServiceInstanceCredentialPropertiesState{
+		Parameters:         parameters,
+		ParametersChecksum: parametersChecksum,
+		UserInfo:           binding.Spec.UserInfo,
+	}
+	setServiceInstanceCredentialCondition(clone, v1alpha1.ServiceInstanceCredentialConditionReady, v1alpha1.ConditionFalse, "Started"+string(op), fmt.Sprintf("The %v operation is in progress", op))
+	c.recorder.Event(binding, v1.EventTypeNormal, successBindingStartedReason, fmt.Sprintf("Starting %v", op))
+	return c.updateServiceInstanceCredentialStatus(clone)
+}
+
+// recordSuccessfulServiceInstanceCredentialOperation finalizes a successful
+// Bind/Unbind: it clears CurrentOperation, advances ReconciledGeneration, and
+// (for Bind) promotes InProgressProperties to ExternalProperties.
+func (c *controller) recordSuccessfulServiceInstanceCredentialOperation(
+	binding *v1alpha1.ServiceInstanceCredential,
+	op v1alpha1.ServiceInstanceCredentialOperation,
+) (*v1alpha1.ServiceInstanceCredential, error) {
+	clone := binding.DeepCopy()
+	clone.Status.CurrentOperation = ""
+	clone.Status.ReconciledGeneration = clone.Generation
+	clone.Status.OperationStartTime = nil
+	if op == v1alpha1.ServiceInstanceCredentialOperationBind {
+		clone.Status.ExternalProperties = clone.Status.InProgressProperties
+	}
+	clone.Status.InProgressProperties = nil
+	setServiceInstanceCredentialCondition(clone, v1alpha1.ServiceInstanceCredentialConditionReady, v1alpha1.ConditionTrue, "Injected"+string(op)+"Result", fmt.Sprintf("The %v operation completed successfully", op))
+	return c.updateServiceInstanceCredentialStatus(clone)
+}
+
+// updateServiceInstanceCredentialCondition sets the condition of conditionType
+// to the given status/reason/message and persists the result via UpdateStatus.
+func (c *controller) updateServiceInstanceCredentialCondition(
+	binding *v1alpha1.ServiceInstanceCredential,
+	conditionType v1alpha1.ServiceInstanceCredentialConditionType,
+	status v1alpha1.ConditionStatus,
+	reason, message string,
+) (*v1alpha1.ServiceInstanceCredential, error) {
+	clone := binding.DeepCopy()
+	setServiceInstanceCredentialCondition(clone, conditionType, status, reason, message)
+	return c.updateServiceInstanceCredentialStatus(clone)
+}
+
+// updateServiceInstanceCredentialStatus persists binding's Status subresource
+// via the catalog client.
+func (c *controller) updateServiceInstanceCredentialStatus(binding *v1alpha1.ServiceInstanceCredential) (*v1alpha1.ServiceInstanceCredential, error) {
+	return c.catalogClient.ServicecatalogV1alpha1().ServiceInstanceCredentials(binding.Namespace).UpdateStatus(binding)
+}
+
+// updateServiceInstanceCredentialFailure sets both Ready=False and
+// Failed=True in a single status update, for the classes of OSB errors that
+// are not retriable (4xx Bind responses, a non-bindable class/plan,
+// malformed Spec.Parameters, a persistent Secret ownership conflict). Once
+// Failed is set, reconcileServiceInstanceCredential short-circuits further
+// attempts until the user bumps the binding's generation. It always clears
+// OrphanMitigationInProgress alongside CurrentOperation/OperationStartTime,
+// since every caller of this helper is abandoning whatever operation (Bind,
+// or the orphan mitigation that can follow one) was in progress — a caller
+// never needs to set OrphanMitigationInProgress back to true in the same
+// update that also calls this helper.
+func (c *controller) updateServiceInstanceCredentialFailure(
+	binding *v1alpha1.ServiceInstanceCredential,
+	reason, message string,
+) (*v1alpha1.ServiceInstanceCredential, error) {
+	clone := binding.DeepCopy()
+	clone.Status.ReconciledGeneration = clone.Generation
+	clone.Status.CurrentOperation = ""
+	clone.Status.OperationStartTime = nil
+	clone.Status.OrphanMitigationInProgress = false
+	setServiceInstanceCredentialCondition(clone, v1alpha1.ServiceInstanceCredentialConditionReady, v1alpha1.ConditionFalse, reason, message)
+	setServiceInstanceCredentialCondition(clone, v1alpha1.ServiceInstanceCredentialConditionFailed, v1alpha1.ConditionTrue, reason, message)
+	return c.updateServiceInstanceCredentialStatus(clone)
+}
+
+// isServiceInstanceCredentialFailed reports whether binding's Failed
+// condition is currently set to True.
+func isServiceInstanceCredentialFailed(binding *v1alpha1.ServiceInstanceCredential) bool {
+	for _, cond := range binding.Status.Conditions {
+		if cond.Type == v1alpha1.ServiceInstanceCredentialConditionFailed {
+			return cond.Status == v1alpha1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// setServiceInstanceCredentialCondition sets the condition of conditionType
+// on binding to status/reason/message, using the current time as the LastTransitionTime basis.
+func setServiceInstanceCredentialCondition(
+	binding *v1alpha1.ServiceInstanceCredential,
+	conditionType v1alpha1.ServiceInstanceCredentialConditionType,
+	status v1alpha1.ConditionStatus,
+	reason, message string,
+) {
+	setServiceInstanceCredentialConditionInternal(binding, conditionType, status, reason, message, metav1.Now())
+}
+
+// setServiceInstanceCredentialConditionInternal does the actual condition
+// bookkeeping: it finds-or-appends a condition of conditionType and only
+// bumps LastTransitionTime when Status actually changes, so repeated
+// reconciles that don't change the binding's state don't churn the object.
+// t is taken as a parameter (rather than computed internally) so tests can
+// pin it for deterministic assertions.
+func setServiceInstanceCredentialConditionInternal(
+	binding *v1alpha1.ServiceInstanceCredential,
+	conditionType v1alpha1.ServiceInstanceCredentialConditionType,
+	status v1alpha1.ConditionStatus,
+	reason, message string,
+	t metav1.Time,
+) {
+	for i, cond := range binding.Status.Conditions {
+		if cond.Type != conditionType {
+			continue
+		}
+		if cond.Status != status {
+			binding.Status.Conditions[i].LastTransitionTime = t
+		}
+		binding.Status.Conditions[i].Status = status
+		binding.Status.Conditions[i].Reason = reason
+		binding.Status.Conditions[i].Message = message
+		return
+	}
+
+	binding.Status.Conditions = append(binding.Status.Conditions, v1alpha1.ServiceInstanceCredentialCondition{
+		Type:               conditionType,
+		Status:             status,
+		LastTransitionTime: t,
+		Reason:             reason,
+		Message:            message,
+	})
+}
+
+// utilfeatureOriginatingIdentityEnabled reports whether the
+// OriginatingIdentity alpha feature is currently enabled.
+func utilfeatureOriginatingIdentityEnabled() bool {
+	return utilfeature.DefaultFeatureGate.Enabled(scfeatures.OriginatingIdentity)
+}
+
+// toOSBOriginatingIdentity converts a ServiceInstanceCredentialSpec's
+// UserInfo into the OSB-wire AlphaOriginatingIdentity, returning nil if
+// userInfo is nil.
+func toOSBOriginatingIdentity(userInfo *v1alpha1.UserInfo) *osb.AlphaOriginatingIdentity {
+	if userInfo == nil {
+		return nil
+	}
+	extra := make(map[string]interface{}, len(userInfo.Extra))
+	for k, v := range userInfo.Extra {
+		extra[k] = []string(v)
+	}
+	b, err := json.Marshal(map[string]interface{}{
+		"username": userInfo.Username,
+		"uid":      userInfo.UID,
+		"groups":   userInfo.Groups,
+		"extra":    extra,
+	})
+	if err != nil {
+		return nil
+	}
+	return &osb.AlphaOriginatingIdentity{
+		Platform: "kubernetes",
+		Value:    string(b),
+	}
+}
@@ -0,0 +1,56 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// GetControllerOf returns the controllerRef owner reference of obj, or nil
+// if it has none.
+func GetControllerOf(obj metav1.Object) *metav1.OwnerReference {
+	for i := range obj.GetOwnerReferences() {
+		ref := &obj.GetOwnerReferences()[i]
+		if ref.Controller != nil && *ref.Controller {
+			return ref
+		}
+	}
+	return nil
+}
+
+// IsControlledBy returns true if owner is the controllerRef owner of obj.
+func IsControlledBy(obj metav1.Object, owner metav1.Object) bool {
+	ref := GetControllerOf(obj)
+	return ref != nil && ref.UID == owner.GetUID()
+}
+
+// generateChecksumOfParameters returns a hex-encoded sha256 checksum of the
+// canonical JSON encoding of parameters. It is recorded on
+// InProgress/ExternalProperties so the controller can detect whether a
+// previously-materialized operation's parameters still match the spec.
+func generateChecksumOfParameters(parameters map[string]interface{}) (string, error) {
+	b, err := json.Marshal(parameters)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal parameters to compute checksum: %v", err)
+	}
+	sum := sha256.Sum256(b)
+	return fmt.Sprintf("%x", sum), nil
+}
@@ -0,0 +1,41 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package features holds the feature gates recognized by the service-catalog
+// controller-manager.
+package features
+
+import (
+	utilfeature "k8s.io/apiserver/pkg/util/feature"
+)
+
+const (
+	// OriginatingIdentity controls whether the controller includes the
+	// identity of the Kubernetes user that last modified a resource's spec
+	// when it calls out to a broker.
+	OriginatingIdentity utilfeature.Feature = "OriginatingIdentity"
+)
+
+// defaultServiceCatalogFeatureGates holds the feature gates this binary
+// recognizes along with whether they default to on or off and how far along
+// they are (alpha features default to off).
+var defaultServiceCatalogFeatureGates = map[utilfeature.Feature]utilfeature.FeatureSpec{
+	OriginatingIdentity: {Default: false, PreRelease: utilfeature.Alpha},
+}
+
+func init() {
+	utilfeature.DefaultFeatureGate.Add(defaultServiceCatalogFeatureGates)
+}